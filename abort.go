@@ -0,0 +1,14 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build go1.8
+// +build go1.8
+
+package httprouter
+
+import "net/http"
+
+// errAbortHandler is the panic value that aborts the response, it isn't
+// recovered by the router.
+var errAbortHandler interface{} = http.ErrAbortHandler
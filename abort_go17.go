@@ -0,0 +1,12 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build !go1.8
+// +build !go1.8
+
+package httprouter
+
+// errAbortHandler is nil, http.ErrAbortHandler was added in Go 1.8 and the
+// recovered panic values are never nil.
+var errAbortHandler interface{}
@@ -0,0 +1,40 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build go1.8
+// +build go1.8
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAbortHandler(t *testing.T) {
+	router := New()
+	// Static routes run in their own goroutine, the ones with parameters
+	// run in the ServeHTTP goroutine.
+	for _, path := range []string{"/static", "/param/:name"} {
+		router.GET(path, false, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic(http.ErrAbortHandler)
+		})
+	}
+
+	for _, path := range []string{"/static", "/param/gopher"} {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", path, nil)
+		recv := catchPanic(func() {
+			router.ServeHTTP(w, r)
+		})
+		if recv != http.ErrAbortHandler {
+			t.Errorf("%v: wrong panic %v", path, recv)
+		}
+		if w.Body.Len() != 0 || w.Code == http.StatusInternalServerError {
+			t.Errorf("%v: response was sent %v %q", path, w.Code, w.Body.String())
+		}
+	}
+}
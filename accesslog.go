@@ -0,0 +1,213 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/fcavani/slog"
+)
+
+// AccessEntry describes one request served by the router.
+type AccessEntry struct {
+	// Time when the request arrived.
+	Time       time.Time
+	RemoteAddr string
+	User       string
+	Method     string
+	URI        string
+	Proto      string
+	Referer    string
+	UserAgent  string
+	// Status code sent to the client.
+	Status int
+	// Number of body bytes sent to the client.
+	Bytes int64
+	// Path pattern of the matched route, empty if no route matched.
+	Pattern string
+	// Language negotiated for the request.
+	Lang string
+	// ID assigned to the request, see Router.RequestIDHeader.
+	RequestID string
+	// Time spent serving the request.
+	Latency time.Duration
+}
+
+// AccessLogger records the requests served by the router.
+type AccessLogger interface {
+	Log(entry *AccessEntry)
+}
+
+func newAccessEntry(req *http.Request, start time.Time) *AccessEntry {
+	uri := req.RequestURI
+	if uri == "" {
+		uri = req.URL.RequestURI()
+	}
+	user, _, _ := req.BasicAuth()
+	return &AccessEntry{
+		Time:       start,
+		RemoteAddr: req.RemoteAddr,
+		User:       user,
+		Method:     req.Method,
+		URI:        uri,
+		Proto:      req.Proto,
+		Referer:    req.Referer(),
+		UserAgent:  req.UserAgent(),
+	}
+}
+
+type lineLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format func([]byte, *AccessEntry) []byte
+}
+
+func (l *lineLogger) Log(entry *AccessEntry) {
+	buf := l.format(make([]byte, 0, 256), entry)
+	l.mu.Lock()
+	l.w.Write(buf)
+	l.mu.Unlock()
+}
+
+// NewCommonLogger returns a logger that writes to w in the Common Log
+// Format.
+func NewCommonLogger(w io.Writer) AccessLogger {
+	return &lineLogger{w: w, format: func(buf []byte, entry *AccessEntry) []byte {
+		return append(appendCommon(buf, entry), '\n')
+	}}
+}
+
+// NewCombinedLogger returns a logger that writes to w in the Combined Log
+// Format.
+func NewCombinedLogger(w io.Writer) AccessLogger {
+	return &lineLogger{w: w, format: func(buf []byte, entry *AccessEntry) []byte {
+		buf = appendCommon(buf, entry)
+		buf = append(buf, ' ')
+		buf = strconv.AppendQuote(buf, entry.Referer)
+		buf = append(buf, ' ')
+		buf = strconv.AppendQuote(buf, entry.UserAgent)
+		return append(buf, '\n')
+	}}
+}
+
+func appendCommon(buf []byte, entry *AccessEntry) []byte {
+	host, _, err := net.SplitHostPort(entry.RemoteAddr)
+	if err != nil {
+		host = entry.RemoteAddr
+	}
+	buf = append(buf, dash(host)...)
+	buf = append(buf, " - "...)
+	buf = append(buf, dash(entry.User)...)
+	buf = append(buf, " ["...)
+	buf = entry.Time.AppendFormat(buf, "02/Jan/2006:15:04:05 -0700")
+	buf = append(buf, "] "...)
+	buf = strconv.AppendQuote(buf, entry.Method+" "+entry.URI+" "+entry.Proto)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(entry.Status), 10)
+	buf = append(buf, ' ')
+	return strconv.AppendInt(buf, entry.Bytes, 10)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+type jsonEntry struct {
+	Time       string  `json:"time"`
+	RemoteAddr string  `json:"remote_addr"`
+	User       string  `json:"user,omitempty"`
+	Method     string  `json:"method"`
+	URI        string  `json:"uri"`
+	Proto      string  `json:"proto"`
+	Referer    string  `json:"referer,omitempty"`
+	UserAgent  string  `json:"user_agent,omitempty"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	Pattern    string  `json:"pattern,omitempty"`
+	Lang       string  `json:"lang,omitempty"`
+	RequestID  string  `json:"request_id,omitempty"`
+	Latency    float64 `json:"latency"`
+}
+
+// NewJSONLogger returns a logger that writes to w one JSON object per line.
+// The latency is in seconds.
+func NewJSONLogger(w io.Writer) AccessLogger {
+	return &lineLogger{w: w, format: func(buf []byte, entry *AccessEntry) []byte {
+		b, err := json.Marshal(&jsonEntry{
+			Time:       entry.Time.Format(time.RFC3339Nano),
+			RemoteAddr: entry.RemoteAddr,
+			User:       entry.User,
+			Method:     entry.Method,
+			URI:        entry.URI,
+			Proto:      entry.Proto,
+			Referer:    entry.Referer,
+			UserAgent:  entry.UserAgent,
+			Status:     entry.Status,
+			Bytes:      entry.Bytes,
+			Pattern:    entry.Pattern,
+			Lang:       entry.Lang,
+			RequestID:  entry.RequestID,
+			Latency:    entry.Latency.Seconds(),
+		})
+		if err != nil {
+			return buf
+		}
+		buf = append(buf, b...)
+		return append(buf, '\n')
+	}}
+}
+
+type slogLogger struct{}
+
+func (slogLogger) Log(entry *AccessEntry) {
+	log.InfoLevel().Tag("httprouter", "statistics").Printf("RequestID=%v, Method=%v, Path=%v, Pattern=%v, Status=%v, Bytes=%v, Lang=%v, Execution=%v", entry.RequestID, entry.Method, entry.URI, entry.Pattern, entry.Status, entry.Bytes, entry.Lang, entry.Latency)
+}
+
+// NewSlogLogger returns a logger that writes the entries with
+// github.com/fcavani/slog at the info level. It is the default logger.
+func NewSlogLogger() AccessLogger {
+	return slogLogger{}
+}
+
+type sampledLogger struct {
+	next  AccessLogger
+	rates map[string]float64
+	mu    sync.Mutex
+	rand  *rand.Rand
+}
+
+// NewSampledLogger returns a logger that sends to next only a fraction of the
+// requests of the busy routes. rates maps the route pattern to the fraction,
+// between 0 and 1, of its requests that are logged. Requests of routes not
+// in rates are always logged.
+func NewSampledLogger(next AccessLogger, rates map[string]float64) AccessLogger {
+	return &sampledLogger{
+		next:  next,
+		rates: rates,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (l *sampledLogger) Log(entry *AccessEntry) {
+	if rate, found := l.rates[entry.Pattern]; found {
+		l.mu.Lock()
+		f := l.rand.Float64()
+		l.mu.Unlock()
+		if f >= rate {
+			return
+		}
+	}
+	l.next.Log(entry)
+}
@@ -0,0 +1,121 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordLogger struct {
+	entries []*AccessEntry
+}
+
+func (l *recordLogger) Log(entry *AccessEntry) {
+	l.entries = append(l.entries, entry)
+}
+
+func TestAccessLog(t *testing.T) {
+	router := New()
+	logger := &recordLogger{}
+	router.AccessLog = logger
+	router.GET("/user/:name", false, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("catotos"))
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/user/gopher?x=1", nil)
+	router.ServeHTTP(w, r)
+	r, _ = http.NewRequest("GET", "/nope", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	if len(logger.entries) != 2 {
+		t.Fatal("wrong number of entries", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if entry.Method != "GET" || entry.URI != "/user/gopher?x=1" || entry.Status != http.StatusCreated ||
+		entry.Bytes != 7 || entry.Pattern != "/user/:name" || entry.Latency <= 0 {
+		t.Fatalf("wrong entry: %+v", entry)
+	}
+	entry = logger.entries[1]
+	if entry.Status != http.StatusNotFound || entry.Pattern != "" {
+		t.Fatalf("wrong entry: %+v", entry)
+	}
+
+	router.AccessLog = nil
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if len(logger.entries) != 2 {
+		t.Fatal("access log not turned off")
+	}
+}
+
+func TestAccessLogFormats(t *testing.T) {
+	entry := &AccessEntry{
+		Time:       time.Date(2000, 10, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600)),
+		RemoteAddr: "127.0.0.1:4321",
+		User:       "frank",
+		Method:     "GET",
+		URI:        "/apache_pb.gif",
+		Proto:      "HTTP/1.0",
+		Referer:    "http://www.example.com/start.html",
+		UserAgent:  "Mozilla/4.08",
+		Status:     200,
+		Bytes:      2326,
+		Pattern:    "/:file",
+		Lang:       "en",
+		Latency:    time.Millisecond,
+	}
+
+	buf := &bytes.Buffer{}
+	NewCommonLogger(buf).Log(entry)
+	common := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`
+	if buf.String() != common+"\n" {
+		t.Errorf("wrong common log: %q", buf.String())
+	}
+
+	buf.Reset()
+	NewCombinedLogger(buf).Log(entry)
+	combined := common + ` "http://www.example.com/start.html" "Mozilla/4.08"`
+	if buf.String() != combined+"\n" {
+		t.Errorf("wrong combined log: %q", buf.String())
+	}
+
+	buf.Reset()
+	NewJSONLogger(buf).Log(entry)
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["pattern"] != "/:file" || m["lang"] != "en" || m["status"] != float64(200) || m["latency"] != 0.001 {
+		t.Errorf("wrong json log: %v", buf.String())
+	}
+}
+
+func TestSampledLogger(t *testing.T) {
+	logger := &recordLogger{}
+	sampled := NewSampledLogger(logger, map[string]float64{
+		"/search/:query": 0,
+		"/account/*path": 1,
+	})
+	for _, pattern := range []string{"/search/:query", "/account/*path", "/"} {
+		for i := 0; i < 10; i++ {
+			sampled.Log(&AccessEntry{Pattern: pattern})
+		}
+	}
+	if len(logger.entries) != 20 {
+		t.Fatal("wrong number of entries", len(logger.entries))
+	}
+	for _, entry := range logger.entries {
+		if strings.HasPrefix(entry.Pattern, "/search") {
+			t.Fatal("sampled route logged")
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// XDefault is the hreflang of the alternate for the clients whose language
+// isn't supported.
+const XDefault = "x-default"
+
+// Alternate is the URL of the requested resource in another language.
+type Alternate struct {
+	// Lang is the language of the URL, the hreflang, or XDefault.
+	Lang string
+	// URL is the absolute URL with the scheme and the host of the
+	// RedirectPolicy, or the root-relative one if the policy has no host.
+	URL string
+}
+
+type alternatesKey struct{}
+
+type alternates struct {
+	router *Router
+	route  *Route
+	url    url.URL
+	// rest is the path without the language prefix, and pathLang the
+	// language of its localized path.
+	rest     string
+	pathLang string
+	ps       Params
+}
+
+// Alternates returns the URLs of the request in all languages of the route,
+// sorted by language, followed by the XDefault one that is the URL in the
+// default language, or in the best language of the route for the default
+// one. Use it in the templates to write the hreflang links:
+//  {{range .Alternates}}
+//  <link rel="alternate" hreflang="{{.Lang}}" href="{{.URL}}">
+//  {{end}}
+// It returns nil if the request isn't of an i18n route.
+func Alternates(req *http.Request) []Alternate {
+	a, ok := req.Context().Value(alternatesKey{}).(*alternates)
+	if !ok {
+		return nil
+	}
+	return a.list()
+}
+
+func (a *alternates) list() []Alternate {
+	set := a.router.SupportedLangs
+	if a.route.Langs != nil {
+		set = a.route.langSet()
+	}
+	langs := make([]string, 0, len(set))
+	for lang := range set {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	list := make([]Alternate, 0, len(langs)+1)
+	for _, lang := range langs {
+		list = append(list, Alternate{Lang: lang, URL: a.langURL(lang)})
+	}
+	if def := a.router.availableLang(a.route, a.router.DefaultLang); def != "" {
+		list = append(list, Alternate{Lang: XDefault, URL: a.langURL(def)})
+	}
+	return list
+}
+
+func (a *alternates) langURL(lang string) string {
+	u := a.url
+	u.Path = "/" + lang + a.route.localPath(lang, a.pathLang, a.rest, a.ps)
+	u.RawPath = ""
+	return u.String()
+}
+
+// setAlternates stores the alternates of the request in its context and, if
+// AlternateLinks is enabled, sends them in Link headers. rest is the path
+// without the language prefix and pathLang the language of its localized
+// path.
+func (r *Router) setAlternates(w http.ResponseWriter, req *http.Request, rt *Route, rest, pathLang string) *http.Request {
+	a := &alternates{
+		router: r,
+		route:  rt,
+		url: url.URL{
+			RawQuery: req.URL.RawQuery,
+		},
+		rest:     rest,
+		pathLang: pathLang,
+		ps:       Parameters(req),
+	}
+	// The host of the request is chosen by the client and the responses may
+	// be cached, only the canonical one is used.
+	if p := r.RedirectPolicy; p != nil && p.Host != "" {
+		a.url.Host = p.Host
+		a.url.Scheme = p.Scheme
+		if a.url.Scheme == "" {
+			a.url.Scheme = "http"
+			if req.TLS != nil {
+				a.url.Scheme = "https"
+			}
+		}
+	}
+	if r.AlternateLinks {
+		header := w.Header()
+		for _, alt := range a.list() {
+			header.Add("Link", "<"+alt.URL+`>; rel="alternate"; hreflang="`+alt.Lang+`"`)
+		}
+	}
+	return req.WithContext(context.WithValue(req.Context(), alternatesKey{}, a))
+}
@@ -0,0 +1,91 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAlternates(t *testing.T) {
+	router := langRouter()
+	router.AlternateLinks = true
+	var alts []Alternate
+	router.GET("/docs/:page", true, func(w http.ResponseWriter, r *http.Request) {
+		alts = Alternates(r)
+	})
+	router.GET("/plain", false, func(w http.ResponseWriter, r *http.Request) {
+		alts = Alternates(r)
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "http://example.com/pt/docs/intro?v=2", nil)
+	router.ServeHTTP(w, r)
+	want := []Alternate{
+		{"en", "/en/docs/intro?v=2"},
+		{"es-ES", "/es-ES/docs/intro?v=2"},
+		{"es-MX", "/es-MX/docs/intro?v=2"},
+		{"pt", "/pt/docs/intro?v=2"},
+		{XDefault, "/en/docs/intro?v=2"},
+	}
+	if !reflect.DeepEqual(alts, want) {
+		t.Fatalf("wrong alternates %v", alts)
+	}
+	links := w.Header()["Link"]
+	if len(links) != 5 || links[1] != `</es-ES/docs/intro?v=2>; rel="alternate"; hreflang="es-ES"` ||
+		links[4] != `</en/docs/intro?v=2>; rel="alternate"; hreflang="x-default"` {
+		t.Fatalf("wrong links %q", links)
+	}
+
+	router.RedirectPolicy = &RedirectPolicy{Scheme: "https", Host: "www.example.com"}
+	router.LangStrategy = ServeLang
+	r, _ = http.NewRequest("GET", "http://example.com/docs/intro", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if len(alts) != 5 || alts[3].URL != "https://www.example.com/pt/docs/intro" {
+		t.Fatalf("wrong alternates %v", alts)
+	}
+
+	r, _ = http.NewRequest("GET", "/plain", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if alts != nil {
+		t.Fatalf("alternates of a route without i18n: %v", alts)
+	}
+}
+
+func TestAlternatesHost(t *testing.T) {
+	router := langRouter()
+	router.AlternateLinks = true
+	var alts []Alternate
+	router.GET("/docs/:page", true, func(w http.ResponseWriter, r *http.Request) {
+		alts = Alternates(r)
+	})
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/pt/docs/intro", nil)
+		r.Host = "evil.example"
+		router.ServeHTTP(w, r)
+		return w
+	}
+	w := get()
+	if len(alts) != 5 || alts[0].URL != "/en/docs/intro" {
+		t.Fatalf("wrong alternates %v", alts)
+	}
+	for _, link := range w.Header()["Link"] {
+		if strings.Contains(link, "evil.example") {
+			t.Fatalf("link with the host of the request: %v", link)
+		}
+	}
+
+	router.RedirectPolicy = &RedirectPolicy{Host: "www.example.com"}
+	w = get()
+	if len(alts) != 5 || alts[0].URL != "http://www.example.com/en/docs/intro" ||
+		w.Header().Get("Link") != `<http://www.example.com/en/docs/intro>; rel="alternate"; hreflang="en"` {
+		t.Fatalf("wrong alternates %v, links %q", alts, w.Header()["Link"])
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"io"
+	"net/http"
+)
+
+// limitedBody is the body of a request with a size limit. It records if the
+// handle tried to read beyond the limit.
+type limitedBody struct {
+	io.ReadCloser
+	max      int64
+	n        int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err != nil && err != io.EOF && b.n >= b.max {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// maxBodySize returns the body size limit of the route, of its groups or of
+// the router, zero if there is none.
+func (r *Router) maxBodySize(rt *Route) int64 {
+	max := rt.MaxBodySize
+	for g := rt.group; g != nil && max == 0; g = g.parent {
+		max = g.MaxBodySize
+	}
+	if max == 0 {
+		max = r.MaxBodySize
+	}
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// limitBody limits the body of the request to the size allowed for the
+// route. It writes the 413 response and returns false if the declared
+// length of the body is already too large.
+func (r *Router) limitBody(w http.ResponseWriter, req *http.Request, rt *Route) (*limitedBody, bool) {
+	max := r.maxBodySize(rt)
+	if max == 0 || req.Body == nil {
+		return nil, true
+	}
+	if req.ContentLength > max {
+		r.bodyTooLarge(w, req)
+		return nil, false
+	}
+	body := &limitedBody{
+		ReadCloser: http.MaxBytesReader(w, req.Body, max),
+		max:        max,
+	}
+	req.Body = body
+	return body, true
+}
+
+// checkBody replaces the response with the 413 response, if the handle read
+// beyond the limit and the response wasn't sent yet. Returns true if the
+// response was replaced.
+func (r *Router) checkBody(w *ResponseWriter, req *http.Request, body *limitedBody) bool {
+	if body == nil || !body.exceeded || w.Committed() {
+		return false
+	}
+	w.Reset()
+	r.bodyTooLarge(w, req)
+	return true
+}
+
+func (r *Router) bodyTooLarge(w http.ResponseWriter, req *http.Request) {
+	if r.BodyTooLarge != nil {
+		r.BodyTooLarge.ServeHTTP(w, req)
+		return
+	}
+	r.httpError(w, req, http.StatusRequestEntityTooLarge, "")
+}
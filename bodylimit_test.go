@@ -0,0 +1,85 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	router := New()
+	router.MaxBodySize = 8
+	uploads := router.Group("/upload")
+	uploads.MaxBodySize = 16
+
+	var readErr error
+	handle := func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Partial", "1")
+		w.Write([]byte("ok"))
+	}
+	// Static routes run in their own goroutine, the ones with parameters
+	// run in the ServeHTTP goroutine.
+	router.POST("/json", false, handle)
+	router.POST("/json/:id", false, handle)
+	uploads.POST("/file", false, handle)
+	router.POST("/free", false, handle).MaxBodySize = -1
+
+	tests := []struct {
+		path   string
+		size   int
+		length bool
+		code   int
+	}{
+		{"/json", 8, true, http.StatusOK},
+		{"/json", 9, true, http.StatusRequestEntityTooLarge},
+		{"/json", 9, false, http.StatusRequestEntityTooLarge},
+		{"/json/1", 9, false, http.StatusRequestEntityTooLarge},
+		{"/upload/file", 16, false, http.StatusOK},
+		{"/upload/file", 17, true, http.StatusRequestEntityTooLarge},
+		{"/free", 1024, true, http.StatusOK},
+	}
+	for _, test := range tests {
+		readErr = nil
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", test.path, strings.NewReader(strings.Repeat("x", test.size)))
+		if !test.length {
+			r.ContentLength = -1
+		}
+		router.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%v %v: wrong status %v", test.path, test.size, w.Code)
+		}
+		if test.code == http.StatusRequestEntityTooLarge {
+			if w.Header().Get("X-Partial") != "" {
+				t.Errorf("%v %v: response of the handle was sent", test.path, test.size)
+			}
+			if !test.length && readErr == nil {
+				t.Errorf("%v %v: handle read beyond the limit", test.path, test.size)
+			}
+		}
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	router := New()
+	router.MaxBodySize = 1
+	router.BodyTooLarge = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte("too big"))
+	})
+	router.POST("/json", false, func(w http.ResponseWriter, r *http.Request) {})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/json", strings.NewReader("xx"))
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge || w.Body.String() != "too big" {
+		t.Fatalf("wrong response %v %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache is an in-memory cache of the responses to GET requests. A response
+// is stored if its status is 200 and it has a Cache-Control header with a
+// max-age, or s-maxage, greater than zero and without no-store, no-cache or
+// private. The responses to requests with the Authorization header are only
+// stored if they have public, s-maxage or must-revalidate, and the requests
+// with Cache-Control: no-cache or no-store aren't served from the cache.
+// Responses are keyed by the method, the host, the path and query, the
+// language negotiated by the router and the request headers listed in the
+// Vary header of the response. The least recently used responses are evicted
+// when the cache grows beyond MaxBytes. A Cache with only MaxBytes set is
+// ready to use.
+type Cache struct {
+	// MaxBytes is the maximum size of the cached responses.
+	MaxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List
+	entries map[string]*list.Element
+	// vary keeps the header names listed in Vary for each base key.
+	vary map[string][]string
+}
+
+type cacheEntry struct {
+	key     string
+	base    string
+	route   string
+	path    string
+	code    int
+	header  http.Header
+	body    []byte
+	size    int64
+	stored  time.Time
+	expires time.Time
+}
+
+// NewCache creates a cache that holds at most maxBytes of responses.
+func NewCache(maxBytes int64) *Cache {
+	return &Cache{
+		MaxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+		vary:     make(map[string][]string),
+	}
+}
+
+// lazyInit allocates the structures of a cache that wasn't created by NewCache.
+// It must be called with the lock held.
+func (c *Cache) lazyInit() {
+	if c.lru == nil {
+		c.lru = list.New()
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+	if c.vary == nil {
+		c.vary = make(map[string][]string)
+	}
+}
+
+// Len returns the number of cached responses.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	return c.lru.Len()
+}
+
+// Size returns the number of bytes used by the cached responses.
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// InvalidateRoute removes all responses of the route with the given name.
+func (c *Cache) InvalidateRoute(name string) {
+	c.invalidate(func(ce *cacheEntry) bool {
+		return ce.route == name
+	})
+}
+
+// InvalidatePrefix removes all responses whose request path begins with
+// prefix.
+func (c *Cache) InvalidatePrefix(prefix string) {
+	c.invalidate(func(ce *cacheEntry) bool {
+		return strings.HasPrefix(ce.path, prefix)
+	})
+}
+
+// Purge removes all responses.
+func (c *Cache) Purge() {
+	c.invalidate(func(*cacheEntry) bool {
+		return true
+	})
+}
+
+func (c *Cache) invalidate(match func(*cacheEntry) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if match(e.Value.(*cacheEntry)) {
+			c.remove(e)
+		}
+		e = next
+	}
+}
+
+func (c *Cache) remove(e *list.Element) {
+	ce := e.Value.(*cacheEntry)
+	c.lru.Remove(e)
+	delete(c.entries, ce.key)
+	c.size -= ce.size
+}
+
+// baseKey returns the key of the request without the Vary headers.
+func baseKey(req *http.Request) string {
+	return req.Method + " " + strings.ToLower(req.Host) + " " + req.URL.RequestURI() + " " + ContentLang(req)
+}
+
+// sharedAuthorized returns true if the response with the Cache-Control
+// header cc may be shared with requests with the Authorization header, see
+// RFC 9111 section 3.5.
+func sharedAuthorized(cc string) bool {
+	return hasToken(cc, "public") || hasToken(cc, "s-maxage") || hasToken(cc, "must-revalidate")
+}
+
+// varyKey adds to base the values of the request headers named in vary.
+func varyKey(base string, vary []string, req *http.Request) string {
+	key := base
+	for _, name := range vary {
+		key += "\n" + name + ":" + strings.Join(req.Header[name], ",")
+	}
+	return key
+}
+
+// load copies the cached response for req into w and returns true, if there
+// is one.
+func (c *Cache) load(w *ResponseWriter, req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	if cc := req.Header.Get("Cache-Control"); hasToken(cc, "no-cache") || hasToken(cc, "no-store") {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	base := baseKey(req)
+	e, found := c.entries[varyKey(base, c.vary[base], req)]
+	if !found {
+		return false
+	}
+	ce := e.Value.(*cacheEntry)
+	if req.Header.Get("Authorization") != "" && !sharedAuthorized(ce.header.Get("Cache-Control")) {
+		return false
+	}
+	now := time.Now()
+	if now.After(ce.expires) {
+		c.remove(e)
+		return false
+	}
+	c.lru.MoveToFront(e)
+
+	header := w.Header()
+	for k, v := range ce.header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set("Age", strconv.Itoa(int(now.Sub(ce.stored)/time.Second)))
+	w.WriteHeader(ce.code)
+	w.Write(ce.body)
+	return true
+}
+
+// store caches the response in w if it is cacheable.
+func (c *Cache) store(w *ResponseWriter, req *http.Request, rt *Route) {
+	if req.Method != http.MethodGet {
+		return
+	}
+	code := w.ResponseCode()
+	if code == 0 {
+		code = http.StatusOK
+	}
+	if code != http.StatusOK || w.Committed() || w.file != nil {
+		return
+	}
+	header := w.Header()
+	if header.Get("Set-Cookie") != "" {
+		return
+	}
+	if hasToken(req.Header.Get("Cache-Control"), "no-store") {
+		return
+	}
+	cc := header.Get("Cache-Control")
+	if req.Header.Get("Authorization") != "" && !sharedAuthorized(cc) {
+		return
+	}
+	maxAge, ok := cacheMaxAge(cc)
+	if !ok {
+		return
+	}
+	var vary []string
+	for _, v := range header["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return
+			}
+			if name != "" {
+				vary = append(vary, name)
+			}
+		}
+	}
+
+	body := append([]byte(nil), w.Bytes()...)
+	ce := &cacheEntry{
+		base:   baseKey(req),
+		path:   req.URL.Path,
+		code:   code,
+		header: make(http.Header, len(header)),
+		body:   body,
+		size:   int64(len(body)),
+		stored: time.Now(),
+	}
+	if rt != nil {
+		ce.route = rt.Name
+	}
+	for k, v := range header {
+		ce.header[k] = append([]string(nil), v...)
+		ce.size += int64(len(k))
+		for _, s := range v {
+			ce.size += int64(len(s))
+		}
+	}
+	ce.key = varyKey(ce.base, vary, req)
+	ce.expires = ce.stored.Add(maxAge)
+	if ce.size > c.MaxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lazyInit()
+	if e, found := c.entries[ce.key]; found {
+		c.remove(e)
+	}
+	c.vary[ce.base] = vary
+	c.entries[ce.key] = c.lru.PushFront(ce)
+	c.size += ce.size
+	for c.size > c.MaxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// cacheMaxAge returns for how long a response with the Cache-Control header
+// cc can be cached.
+func cacheMaxAge(cc string) (time.Duration, bool) {
+	var maxAge, sMaxAge int64 = -1, -1
+	for _, d := range strings.Split(cc, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		switch {
+		case d == "no-store", d == "no-cache", d == "private",
+			strings.HasPrefix(d, "no-cache="), strings.HasPrefix(d, "private="):
+			return 0, false
+		case strings.HasPrefix(d, "max-age="):
+			maxAge, _ = strconv.ParseInt(strings.Trim(d[8:], `"`), 10, 64)
+		case strings.HasPrefix(d, "s-maxage="):
+			sMaxAge, _ = strconv.ParseInt(strings.Trim(d[9:], `"`), 10, 64)
+		}
+	}
+	if sMaxAge > -1 {
+		maxAge = sMaxAge
+	}
+	if maxAge <= 0 {
+		return 0, false
+	}
+	return time.Duration(maxAge) * time.Second, true
+}
@@ -0,0 +1,248 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	router := New()
+	router.Cache = NewCache(1 << 20)
+
+	calls := 0
+	router.GET("/page/:id", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Write([]byte("page " + Parameters(r).ByName("id")))
+	}).Name = "page"
+	router.GET("/vary", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "X-Foo")
+		w.Write([]byte(r.Header.Get("X-Foo")))
+	})
+	router.GET("/nostore", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "no-store, max-age=60")
+	})
+
+	get := func(path, foo string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", path, nil)
+		if foo != "" {
+			r.Header.Set("X-Foo", foo)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	get("/page/1", "")
+	w := get("/page/1", "")
+	if calls != 1 || w.Body.String() != "page 1" || w.Header().Get("Age") == "" {
+		t.Fatalf("cache miss: calls=%v body=%q header=%v", calls, w.Body.String(), w.Header())
+	}
+	get("/page/2", "")
+	if calls != 2 {
+		t.Fatal("wrong hit")
+	}
+
+	calls = 0
+	get("/vary", "a")
+	get("/vary", "b")
+	if w := get("/vary", "a"); calls != 2 || w.Body.String() != "a" {
+		t.Fatalf("vary failed: calls=%v body=%q", calls, w.Body.String())
+	}
+
+	calls = 0
+	get("/nostore", "")
+	get("/nostore", "")
+	if calls != 2 {
+		t.Fatal("no-store cached")
+	}
+
+	calls = 0
+	router.Cache.InvalidateRoute("page")
+	get("/page/1", "")
+	get("/vary", "a")
+	if calls != 1 {
+		t.Fatal("route invalidation failed", calls)
+	}
+	router.Cache.InvalidatePrefix("/va")
+	get("/page/1", "")
+	get("/vary", "a")
+	if calls != 2 {
+		t.Fatal("prefix invalidation failed", calls)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := NewCache(100)
+	body := strings.Repeat("x", 40)
+	for _, path := range []string{"/a", "/b", "/c"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := NewResponseWriter()
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(body))
+		c.store(w, r, nil)
+		if c.Size() > 100 {
+			t.Fatal("cache too big", c.Size())
+		}
+	}
+	if c.Len() != 1 {
+		t.Fatal("wrong number of entries", c.Len())
+	}
+	r, _ := http.NewRequest("GET", "/c", nil)
+	if !c.load(NewResponseWriter(), r) {
+		t.Fatal("most recent entry evicted")
+	}
+	r, _ = http.NewRequest("GET", "/a", nil)
+	if c.load(NewResponseWriter(), r) {
+		t.Fatal("old entry not evicted")
+	}
+}
+
+func TestCacheMaxAge(t *testing.T) {
+	tests := []struct {
+		cc     string
+		maxAge time.Duration
+		ok     bool
+	}{
+		{"max-age=10", 10 * time.Second, true},
+		{"public, max-age=10, s-maxage=20", 20 * time.Second, true},
+		{"max-age=0", 0, false},
+		{"private, max-age=10", 0, false},
+		{"no-cache", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		maxAge, ok := cacheMaxAge(test.cc)
+		if maxAge != test.maxAge || ok != test.ok {
+			t.Errorf("%q: got %v %v", test.cc, maxAge, ok)
+		}
+	}
+}
+
+func TestCacheZeroValue(t *testing.T) {
+	router := New()
+	router.Cache = &Cache{MaxBytes: 1 << 20}
+	calls := 0
+	router.GET("/page", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("page"))
+	})
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/page", nil)
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || w.Body.String() != "page" {
+			t.Fatalf("wrong response %v %q", w.Code, w.Body.String())
+		}
+	}
+	if calls != 1 || router.Cache.Len() != 1 {
+		t.Fatalf("not cached: calls=%v len=%v", calls, router.Cache.Len())
+	}
+	(&Cache{}).Purge()
+}
+
+func TestCacheETag(t *testing.T) {
+	router := New()
+	router.Cache = NewCache(1 << 20)
+	calls := 0
+	router.GET("/page", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("page"))
+	}).ETag = StrongETag
+
+	get := func(inm string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/page", nil)
+		if inm != "" {
+			r.Header.Set("If-None-Match", inm)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	etag := get("").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if w := get(""); w.Code != http.StatusOK || w.Header().Get("ETag") != etag || w.Header().Get("Age") == "" {
+		t.Fatalf("wrong cached response %v %v", w.Code, w.Header())
+	}
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("If-None-Match ignored on a hit: %v %q", w.Code, w.Body.String())
+	}
+	if calls != 1 {
+		t.Fatalf("handle called %v times", calls)
+	}
+}
+
+func TestCacheShared(t *testing.T) {
+	router := New()
+	router.Cache = NewCache(1 << 20)
+	calls := 0
+	router.GET("/x", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte("host=" + r.Host + " auth=" + r.Header.Get("Authorization")))
+	})
+	router.GET("/public", false, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Write([]byte("public"))
+	})
+
+	get := func(url, auth, cc string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", url, nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		if cc != "" {
+			r.Header.Set("Cache-Control", cc)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	// The responses to authorized requests aren't stored.
+	get("http://evil.example/x", "Bearer alice", "")
+	if router.Cache.Len() != 0 {
+		t.Fatal("private response was stored")
+	}
+	// Nor are they shared between hosts.
+	get("http://evil.example/x", "", "")
+	if w := get("http://good.example/x", "", ""); w.Body.String() != "host=good.example auth=" {
+		t.Fatalf("response of another host was served: %q", w.Body.String())
+	}
+	// Authorized requests don't get the stored responses.
+	calls = 0
+	if w := get("http://good.example/x", "Bearer alice", ""); calls != 1 || w.Body.String() != "host=good.example auth=Bearer alice" {
+		t.Fatalf("stored response served to an authorized request: %q", w.Body.String())
+	}
+	// Unless they are public.
+	get("http://good.example/public", "Bearer alice", "")
+	get("http://good.example/public", "Bearer bob", "")
+	if calls != 2 {
+		t.Fatalf("public response wasn't shared: calls=%v", calls)
+	}
+
+	// The requests may refuse the stored responses.
+	calls = 0
+	get("http://good.example/x", "", "no-cache")
+	get("http://good.example/x", "", "max-age=0, no-store")
+	get("http://good.example/x", "", "")
+	if calls != 2 {
+		t.Fatalf("wrong number of calls %v", calls)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressTypes is the content type allowlist used when
+// Compression.Types is empty.
+var DefaultCompressTypes = []string{
+	"text/*",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/problem+json",
+	"image/svg+xml",
+}
+
+// Compression configures the gzip and deflate compression of the responses.
+// The encoding is negotiated with the Accept-Encoding header of the request.
+type Compression struct {
+	// Compression level, see compress/flate. Zero means the default level.
+	Level int
+	// Responses smaller than MinSize bytes are sent uncompressed.
+	MinSize int64
+	// Allowlist of content types that are compressed. A type ending in
+	// "/*" matches all its subtypes. If empty DefaultCompressTypes is used.
+	Types []string
+}
+
+var encodings = map[string]struct{}{
+	"gzip":    struct{}{},
+	"deflate": struct{}{},
+}
+
+// negotiate selects the encoding accepted by the client, returns an empty
+// string if none is accepted.
+func (c *Compression) negotiate(req *http.Request) string {
+	accept := req.Header.Get("Accept-Encoding")
+	if accept == "" {
+		return ""
+	}
+	tps, err := Parse(strings.ToLower(accept))
+	if err != nil {
+		return ""
+	}
+	best := tps.FindBest(encodings)
+	q := tps.rankType(best)
+	if best == "" || q <= 0 {
+		return ""
+	}
+	// FindBest breaks ties by the length of the name, gzip is preferred.
+	if tps.rankType("gzip") == q {
+		return "gzip"
+	}
+	return best
+}
+
+// allowed returns true if the content type is in the allowlist.
+func (c *Compression) allowed(contentType string) bool {
+	types := c.Types
+	if len(types) == 0 {
+		types = DefaultCompressTypes
+	}
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, t := range types {
+		if strings.HasSuffix(t, "/*") {
+			if strings.HasPrefix(contentType, t[:len(t)-1]) {
+				return true
+			}
+		} else if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// encoder adjusts the headers of the response and returns the writer that
+// compresses the body to dst, or nil if the response isn't compressed.
+// size is the length of the body and sample its first bytes.
+func (rw *ResponseWriter) encoder(dst io.Writer, size int64, sample []byte) io.WriteCloser {
+	c := rw.compress
+	if c == nil {
+		return nil
+	}
+	switch {
+	case rw.code == http.StatusNoContent, rw.code == http.StatusNotModified:
+		return nil
+	case rw.code >= 100 && rw.code < 200:
+		return nil
+	}
+	header := rw.header
+	if header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	ct := header.Get("Content-Type")
+	if ct == "" {
+		if size == 0 {
+			return nil
+		}
+		ct = http.DetectContentType(sample)
+		header.Set("Content-Type", ct)
+	}
+	if !c.allowed(ct) {
+		return nil
+	}
+	addVary(header, "Accept-Encoding")
+	if rw.encoding == "" || size < c.MinSize {
+		return nil
+	}
+
+	level := c.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	var enc io.WriteCloser
+	var err error
+	switch rw.encoding {
+	case "gzip":
+		enc, err = gzip.NewWriterLevel(dst, level)
+	case "deflate":
+		// The deflate coding is the zlib format, not raw DEFLATE.
+		enc, err = zlib.NewWriterLevel(dst, level)
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+	header.Del("Content-Length")
+	header.Set("Content-Encoding", rw.encoding)
+	// A strong ETag identifies the bytes sent, so the encoded body needs its
+	// own tag.
+	if etag := header.Get("ETag"); len(etag) > 1 && etag[0] == '"' {
+		header.Set("ETag", etag[:len(etag)-1]+"-"+rw.encoding+`"`)
+	}
+	return enc
+}
+
+// addVary adds field to the Vary header if it isn't there yet.
+func addVary(header http.Header, field string) {
+	for _, v := range header["Vary"] {
+		if hasToken(v, field) || strings.TrimSpace(v) == "*" {
+			return
+		}
+	}
+	header.Add("Vary", field)
+}
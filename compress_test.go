@@ -0,0 +1,122 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	body := strings.Repeat("catotos ", 64)
+	router := New()
+	router.Compression = &Compression{MinSize: 64}
+	router.GET("/text", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(body))
+	})
+	router.GET("/small", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("catotos"))
+	})
+	router.GET("/png", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(body))
+	})
+
+	tests := []struct {
+		path     string
+		accept   string
+		encoding string
+		vary     bool
+	}{
+		{"/text", "gzip, deflate", "gzip", true},
+		{"/text", "deflate", "deflate", true},
+		{"/text", "gzip;q=0.5, deflate", "deflate", true},
+		{"/text", "gzip;q=0", "", true},
+		{"/text", "br", "", true},
+		{"/text", "", "", true},
+		{"/small", "gzip", "", true},
+		{"/png", "gzip", "", false},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.path, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept-Encoding", test.accept)
+		}
+		router.ServeHTTP(w, r)
+		if enc := w.Header().Get("Content-Encoding"); enc != test.encoding {
+			t.Errorf("%v %q: wrong encoding %q", test.path, test.accept, enc)
+			continue
+		}
+		if vary := w.Header().Get("Vary") == "Accept-Encoding"; vary != test.vary {
+			t.Errorf("%v %q: wrong vary %v", test.path, test.accept, w.Header())
+		}
+		var rd io.Reader = w.Body
+		switch test.encoding {
+		case "gzip":
+			gz, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rd = gz
+		case "deflate":
+			zr, err := zlib.NewReader(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rd = zr
+		}
+		buf, err := ioutil.ReadAll(rd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.path == "/text" && string(buf) != body {
+			t.Errorf("%v %q: wrong body", test.path, test.accept)
+		}
+	}
+}
+
+func TestCompressionStream(t *testing.T) {
+	body := strings.Repeat("catotos ", 64)
+	router := New()
+	router.MaxBufferSize = 100
+	router.BufferOverflow = Stream
+	router.Compression = &Compression{}
+	router.GET("/text", false, func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 4; i++ {
+			w.Write([]byte(body))
+		}
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/text", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	router.ServeHTTP(w, r)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("wrong headers: %v", w.Header())
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("wrong content type: %v", w.Header())
+	}
+	gz, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != strings.Repeat(body, 4) {
+		t.Fatal("wrong body")
+	}
+}
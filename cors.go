@@ -0,0 +1,140 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS is the Cross-Origin Resource Sharing policy of the router. The
+// preflight requests are answered by the automatic OPTIONS handling, see
+// Router.HandleOPTIONS, with the methods registered for the path, and the
+// other responses to allowed origins get the CORS headers.
+type CORS struct {
+	// Origins allowed to make requests, "*" allows all origins. An origin may
+	// have one "*" wildcard, like "https://*.example.com". With
+	// AllowCredentials "*" allows no origin, the Fetch standard forbids it,
+	// the origins must be listed.
+	AllowedOrigins []string
+	// Request headers allowed in the requests, "*" allows all headers.
+	AllowedHeaders []string
+	// Response headers exposed to the client.
+	ExposedHeaders []string
+	// If true the requests may have credentials, like cookies.
+	AllowCredentials bool
+	// For how long the client may cache the preflight response. Zero omits
+	// the Access-Control-Max-Age header.
+	MaxAge time.Duration
+}
+
+// allowedOrigin returns the value of the Access-Control-Allow-Origin header
+// for origin, or an empty string if the origin isn't allowed.
+func (c *CORS) allowedOrigin(origin string) string {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" {
+			if c.AllowCredentials {
+				continue
+			}
+			return "*"
+		}
+		if matchOrigin(o, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+func matchOrigin(pattern, origin string) bool {
+	pattern = strings.ToLower(pattern)
+	origin = strings.ToLower(origin)
+	i := strings.IndexByte(pattern, '*')
+	if i < 0 {
+		return pattern == origin
+	}
+	prefix, suffix := pattern[:i], pattern[i+1:]
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
+
+// allowedHeaders returns true if all headers in the comma separated list
+// are allowed.
+func (c *CORS) allowedHeaders(list string) bool {
+	for _, h := range strings.Split(list, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		found := false
+		for _, a := range c.AllowedHeaders {
+			if a == "*" || strings.EqualFold(a, h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// isPreflight returns true if req is a CORS preflight request.
+func isPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get("Origin") != "" &&
+		req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// preflight answers the preflight request. allow is the list of methods of
+// the path.
+func (c *CORS) preflight(w http.ResponseWriter, req *http.Request, allow string) {
+	header := w.Header()
+	addVary(header, "Origin")
+	addVary(header, "Access-Control-Request-Method")
+	addVary(header, "Access-Control-Request-Headers")
+	origin := c.allowedOrigin(req.Header.Get("Origin"))
+	method := req.Header.Get("Access-Control-Request-Method")
+	headers := req.Header.Get("Access-Control-Request-Headers")
+	if origin == "" || !hasToken(allow, method) || !c.allowedHeaders(headers) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Methods", allow)
+	if headers != "" {
+		header.Set("Access-Control-Allow-Headers", headers)
+	}
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if c.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.FormatInt(int64(c.MaxAge/time.Second), 10))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decorate adds the CORS headers to the response of a request that isn't a
+// preflight.
+func (c *CORS) decorate(header http.Header, req *http.Request) {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	addVary(header, "Origin")
+	allowed := c.allowedOrigin(origin)
+	if allowed == "" {
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", allowed)
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(c.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMatchOrigin(t *testing.T) {
+	tests := []struct {
+		pattern, origin string
+		match           bool
+	}{
+		{"https://example.com", "https://example.com", true},
+		{"https://example.com", "https://EXAMPLE.com", true},
+		{"https://example.com", "http://example.com", false},
+		{"https://*.example.com", "https://api.example.com", true},
+		{"https://*.example.com", "https://.example.com", false},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://evil.com", false},
+	}
+	for _, test := range tests {
+		if m := matchOrigin(test.pattern, test.origin); m != test.match {
+			t.Errorf("matchOrigin(%q, %q) = %v", test.pattern, test.origin, m)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	router := New()
+	router.CORS = &CORS{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	handle := func(w http.ResponseWriter, r *http.Request) {}
+	router.GET("/items/:id", false, handle)
+	router.PUT("/items/:id", false, handle)
+
+	preflight := func(origin, method, headers string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("OPTIONS", "/items/1", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			r.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := preflight("https://app.example.com", "PUT", "content-type")
+	h := w.Header()
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("wrong status %v", w.Code)
+	}
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		h.Get("Access-Control-Allow-Methods") != "GET, OPTIONS, PUT" ||
+		h.Get("Access-Control-Allow-Headers") != "content-type" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Max-Age") != "600" ||
+		h.Get("Allow") != "GET, OPTIONS, PUT" {
+		t.Fatalf("wrong preflight headers %v", h)
+	}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		preflight("https://evil.com", "PUT", ""),
+		preflight("https://app.example.com", "DELETE", ""),
+		preflight("https://app.example.com", "PUT", "X-Secret"),
+	} {
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Fatalf("preflight was allowed: %v", w.Header())
+		}
+	}
+}
+
+func TestCORSDecorate(t *testing.T) {
+	router := New()
+	router.CORS = &CORS{
+		AllowedOrigins: []string{"*"},
+		ExposedHeaders: []string{"X-Total"},
+	}
+	router.GET("/items/:id", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("item"))
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/items/1", nil)
+	r.Header.Set("Origin", "https://other.org")
+	router.ServeHTTP(w, r)
+	h := w.Header()
+	if h.Get("Access-Control-Allow-Origin") != "*" || h.Get("Access-Control-Expose-Headers") != "X-Total" ||
+		h.Get("Access-Control-Allow-Credentials") != "" || h.Get("Vary") != "Origin" {
+		t.Fatalf("wrong CORS headers %v", h)
+	}
+
+	// Responses built by the router keep the headers.
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/nope", nil)
+	r.Header.Set("Origin", "https://other.org")
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("missing CORS headers in %v response", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/items/1", nil)
+	router.ServeHTTP(w, r)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("CORS headers without Origin")
+	}
+}
+
+func TestCORSAnyOriginWithCredentials(t *testing.T) {
+	router := New()
+	router.CORS = &CORS{
+		AllowedOrigins:   []string{"*", "https://app.example.com"},
+		AllowCredentials: true,
+	}
+	router.GET("/account", false, func(w http.ResponseWriter, r *http.Request) {})
+
+	for origin, allowed := range map[string]string{
+		"https://attacker.example": "",
+		"https://app.example.com":  "https://app.example.com",
+	} {
+		for _, method := range []string{"GET", "OPTIONS"} {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(method, "/account", nil)
+			r.Header.Set("Origin", origin)
+			r.Header.Set("Access-Control-Request-Method", "GET")
+			router.ServeHTTP(w, r)
+			h := w.Header()
+			if h.Get("Access-Control-Allow-Origin") != allowed {
+				t.Errorf("%v %v: wrong allowed origin %q", method, origin, h.Get("Access-Control-Allow-Origin"))
+			}
+			if (h.Get("Access-Control-Allow-Credentials") != "") != (allowed != "") {
+				t.Errorf("%v %v: wrong credentials header %v", method, origin, h)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+
+	"github.com/fcavani/e"
+	log "github.com/fcavani/slog"
+)
+
+// ErrHandlerFunc is a request handle that returns an error instead of
+// writing the error response itself, see Router.HandleErr.
+type ErrHandlerFunc func(http.ResponseWriter, *http.Request) error
+
+// StatusError is an error that carries the status code of the response.
+type StatusError interface {
+	error
+	Status() int
+}
+
+type statusError struct {
+	code int
+	err  error
+}
+
+func (s *statusError) Error() string {
+	return s.err.Error()
+}
+
+func (s *statusError) Status() int {
+	return s.code
+}
+
+// NewStatusError returns an error with the status code of the response.
+func NewStatusError(code int, err error) error {
+	return &statusError{code: code, err: err}
+}
+
+// ErrorCode maps an error to a status code, see Router.ErrorCodes.
+type ErrorCode struct {
+	Err  error
+	Code int
+}
+
+// ErrorStatus returns the status code of the response to err. It is the
+// code of a StatusError, the code of the first entry of ErrorCodes whose
+// error is equal to err, see e.Equal, or 500 (Internal Server Error).
+func (r *Router) ErrorStatus(err error) int {
+	if se, ok := err.(StatusError); ok {
+		return se.Status()
+	}
+	for _, ec := range r.ErrorCodes {
+		if e.Equal(err, ec.Err) {
+			return ec.Code
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// HandleErr registers a handle that returns an error, see Router.Handle.
+// When the handle returns an error the response it wrote is discarded, if
+// it wasn't sent yet, and the error is handled by ErrorHandler.
+func (r *Router) HandleErr(method, path string, i18n bool, handle ErrHandlerFunc) *Route {
+	return r.Handle(method, path, i18n, r.errHandle(handle))
+}
+
+// HandleErr registers a handle that returns an error, see Router.HandleErr.
+func (g *Group) HandleErr(method, path string, i18n bool, handle ErrHandlerFunc) *Route {
+	return g.Handle(method, path, i18n, g.router.errHandle(handle))
+}
+
+func (r *Router) errHandle(handle ErrHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if err := handle(w, req); err != nil {
+			r.handleError(w, req, err)
+		}
+	}
+}
+
+func (r *Router) handleError(w http.ResponseWriter, req *http.Request, err error) {
+	log.DebugLevel().Tag("httprouter", "error").Printf("Handler returned an error. RequestID=%v Method=%v Path=%v Err: %v", RequestID(req), req.Method, req.URL.Path, err)
+	if !discard(w) {
+		return
+	}
+	if r.ErrorHandler != nil {
+		r.ErrorHandler(w, req, err)
+		return
+	}
+	r.httpError(w, req, r.ErrorStatus(err), "")
+}
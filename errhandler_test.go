@@ -0,0 +1,92 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fcavani/e"
+)
+
+const errNotFound = "not found"
+
+func TestErrorStatus(t *testing.T) {
+	router := New()
+	router.ErrorCodes = []ErrorCode{
+		{e.New(errNotFound), http.StatusNotFound},
+	}
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{NewStatusError(http.StatusBadRequest, errors.New("bad")), http.StatusBadRequest},
+		{e.New(errNotFound), http.StatusNotFound},
+		{e.Push(e.New(errNotFound), "can't load user"), http.StatusNotFound},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if code := router.ErrorStatus(test.err); code != test.code {
+			t.Errorf("ErrorStatus(%v) = %v, want %v", test.err, code, test.code)
+		}
+	}
+}
+
+func TestHandleErr(t *testing.T) {
+	router := New()
+	router.ErrorCodes = []ErrorCode{
+		{e.New(errNotFound), http.StatusNotFound},
+	}
+	handle := func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("X-Partial", "1")
+		w.Write([]byte("partial"))
+		switch r.URL.Query().Get("err") {
+		case "notfound":
+			return e.Push(e.New(errNotFound), "can't load user")
+		case "bad":
+			return NewStatusError(http.StatusBadRequest, errors.New("bad"))
+		}
+		return nil
+	}
+	// Static routes run in their own goroutine, the ones with parameters
+	// run in the ServeHTTP goroutine.
+	router.HandleErr("GET", "/user", false, handle)
+	router.Group("/api").HandleErr("GET", "/user/:name", false, handle)
+
+	tests := []struct {
+		url  string
+		code int
+		body string
+	}{
+		{"/user", http.StatusOK, "partial"},
+		{"/user?err=notfound", http.StatusNotFound, "Not Found\n"},
+		{"/api/user/gopher?err=bad", http.StatusBadRequest, "Bad Request\n"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != test.code || w.Body.String() != test.body {
+			t.Errorf("%v: wrong response %v %q", test.url, w.Code, w.Body.String())
+		}
+		if test.code != http.StatusOK && w.Header().Get("X-Partial") != "" {
+			t.Errorf("%v: partial headers were sent", test.url)
+		}
+	}
+
+	var handled error
+	router.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+		w.WriteHeader(http.StatusTeapot)
+	}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/user?err=bad", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusTeapot || handled == nil || w.Body.Len() != 0 {
+		t.Fatalf("error handler wasn't used: %v %v", w.Code, handled)
+	}
+}
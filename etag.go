@@ -0,0 +1,169 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ETagMode selects the kind of ETag computed for the responses of a route.
+type ETagMode uint8
+
+const (
+	// NoETag disables the ETag and the conditional GET handling.
+	NoETag ETagMode = iota
+	// StrongETag computes a strong ETag from the response body.
+	StrongETag
+	// WeakETag computes a weak ETag, W/"...", from the response body.
+	WeakETag
+)
+
+// notModifiedHeaders are the headers kept in a 304 response.
+var notModifiedHeaders = []string{
+	"Cache-Control",
+	"Content-Location",
+	"Date",
+	"ETag",
+	"Expires",
+	"Last-Modified",
+	"Vary",
+}
+
+// setETag sets the ETag of a buffered response to a GET or HEAD request,
+// unless it already carries one. It returns false if the response has no
+// ETag, or has Cache-Control: no-store, and so is left alone by the
+// conditional handling.
+func setETag(w *ResponseWriter, req *http.Request, mode ETagMode) bool {
+	if mode == NoETag {
+		return false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if code := w.ResponseCode(); code != 0 && code != http.StatusOK {
+		return false
+	}
+	if w.Committed() {
+		return false
+	}
+	header := w.Header()
+	if hasToken(header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	if header.Get("ETag") != "" {
+		return true
+	}
+	etag, err := computeETag(w, mode)
+	if err != nil {
+		return false
+	}
+	header.Set("ETag", etag)
+	return true
+}
+
+// conditional answers the conditional requests with 304 Not Modified if the
+// ETag or the Last-Modified header of the response match, see setETag.
+func conditional(w *ResponseWriter, req *http.Request) {
+	header := w.Header()
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		if matchETag(inm, header.Get("ETag")) {
+			notModified(w)
+		}
+		return
+	}
+	lm := header.Get("Last-Modified")
+	ims := req.Header.Get("If-Modified-Since")
+	if lm == "" || ims == "" {
+		return
+	}
+	lmt, err := http.ParseTime(lm)
+	if err != nil {
+		return
+	}
+	imst, err := http.ParseTime(ims)
+	if err != nil {
+		return
+	}
+	if !lmt.After(imst) {
+		notModified(w)
+	}
+}
+
+// computeETag hashes the body of w.
+func computeETag(w *ResponseWriter, mode ETagMode) (string, error) {
+	h := sha1.New()
+	if w.file != nil {
+		_, err := w.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, w.file)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		h.Write(w.Bytes())
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+	if mode == WeakETag {
+		etag = "W/" + etag
+	}
+	return etag, nil
+}
+
+// matchETag does the weak comparison of etag with the list in the
+// If-None-Match header. The tags of compressed bodies match the tag of the
+// uncompressed one.
+func matchETag(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+		for enc := range encodings {
+			if tag == strings.TrimSuffix(etag, `"`)+"-"+enc+`"` {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// notModified replaces the response in w with a 304 Not Modified.
+func notModified(w *ResponseWriter) {
+	header := w.Header()
+	keep := make(http.Header, len(notModifiedHeaders))
+	for _, k := range notModifiedHeaders {
+		k = http.CanonicalHeaderKey(k)
+		if v, found := header[k]; found {
+			keep[k] = v
+		}
+	}
+	w.Reset()
+	for k, v := range keep {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// hasToken returns true if the comma separated list of directives contains
+// token.
+func hasToken(list, token string) bool {
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if i := strings.IndexByte(s, '='); i >= 0 {
+			s = s[:i]
+		}
+		if strings.EqualFold(s, token) {
+			return true
+		}
+	}
+	return false
+}
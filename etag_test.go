@@ -0,0 +1,95 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestETag(t *testing.T) {
+	router := New()
+	router.GET("/etag", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("catotos"))
+	}).ETag = StrongETag
+	router.GET("/weak", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("catotos"))
+	}).ETag = WeakETag
+	router.GET("/nostore", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("catotos"))
+	}).ETag = StrongETag
+	router.GET("/off", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("catotos"))
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/etag", nil)
+	router.ServeHTTP(w, r)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("wrong response: %v %v", w.Code, etag)
+	}
+
+	w = httptest.NewRecorder()
+	r.Header.Set("If-None-Match", `"foo", `+etag)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("wrong response: %v %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Fatal("etag missing in 304")
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/weak", nil)
+	router.ServeHTTP(w, r)
+	if weak := w.Header().Get("ETag"); weak != "W/"+etag {
+		t.Fatalf("wrong weak etag: %v", weak)
+	}
+	w = httptest.NewRecorder()
+	r.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("wrong response: %v", w.Code)
+	}
+
+	for _, path := range []string{"/nostore", "/off"} {
+		w = httptest.NewRecorder()
+		r, _ = http.NewRequest("GET", path, nil)
+		r.Header.Set("If-None-Match", "*")
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+			t.Fatalf("%v: wrong response: %v %v", path, w.Code, w.Header())
+		}
+	}
+}
+
+func TestIfModifiedSince(t *testing.T) {
+	modified := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	router := New()
+	router.GET("/page", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Write([]byte("catotos"))
+	}).ETag = WeakETag
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/page", nil)
+	r.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("wrong response: %v", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.Header.Set("If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat))
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != "catotos" {
+		t.Fatalf("wrong response: %v", w.Code)
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Group registers routes under a common path prefix. The exported fields are
+// options shared by the routes of the group, a route option overrides the
+// option of its group and a group option overrides the one of the parent
+// group.
+type Group struct {
+	router *Router
+	parent *Group
+	prefix string
+
+	// RateLimit limits the requests to the routes of the group.
+	RateLimit *RateLimiter
+
+	// MaxBodySize is the maximum size in bytes of the request bodies, see
+	// Route.MaxBodySize.
+	MaxBodySize int64
+}
+
+// Group creates a group of routes whose paths begin with prefix.
+func (r *Router) Group(prefix string) *Group {
+	return newGroup(r, nil, prefix)
+}
+
+// Group creates a subgroup whose paths begin with the prefix of g followed
+// by prefix.
+func (g *Group) Group(prefix string) *Group {
+	return newGroup(g.router, g, g.prefix+prefix)
+}
+
+func newGroup(r *Router, parent *Group, prefix string) *Group {
+	if len(prefix) < 1 || prefix[0] != '/' {
+		panic("prefix must begin with '/' in prefix '" + prefix + "'")
+	}
+	return &Group{
+		router: r,
+		parent: parent,
+		prefix: strings.TrimRight(prefix, "/"),
+	}
+}
+
+// Prefix returns the path prefix of the group.
+func (g *Group) Prefix() string {
+	return g.prefix
+}
+
+// GET is a shortcut for group.Handle(http.MethodGet, path, i18n, handle)
+func (g *Group) GET(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodGet, path, i18n, handle)
+}
+
+// HEAD is a shortcut for group.Handle(http.MethodHead, path, i18n, handle)
+func (g *Group) HEAD(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodHead, path, i18n, handle)
+}
+
+// OPTIONS is a shortcut for group.Handle(http.MethodOptions, path, i18n, handle)
+func (g *Group) OPTIONS(path string, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodOptions, path, false, handle)
+}
+
+// POST is a shortcut for group.Handle(http.MethodPost, path, i18n, handle)
+func (g *Group) POST(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodPost, path, i18n, handle)
+}
+
+// PUT is a shortcut for group.Handle(http.MethodPut, path, i18n, handle)
+func (g *Group) PUT(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodPut, path, i18n, handle)
+}
+
+// PATCH is a shortcut for group.Handle(http.MethodPatch, path, i18n, handle)
+func (g *Group) PATCH(path string, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodPatch, path, false, handle)
+}
+
+// DELETE is a shortcut for group.Handle(http.MethodDelete, path, i18n, handle)
+func (g *Group) DELETE(path string, handle http.HandlerFunc) *Route {
+	return g.Handle(http.MethodDelete, path, false, handle)
+}
+
+// Handle registers a new request handle with the prefix of the group
+// followed by path, see Router.Handle.
+func (g *Group) Handle(method, path string, i18n bool, handle http.HandlerFunc) *Route {
+	if len(path) < 1 || path[0] != '/' {
+		panic("path must begin with '/' in path '" + path + "'")
+	}
+	rt := g.router.Handle(method, g.prefix+path, i18n, handle)
+	rt.group = g
+	return rt
+}
+
+// Handler is an adapter which allows the usage of an http.Handler as a
+// request handle of the group.
+func (g *Group) Handler(method, path string, i18n bool, handler http.Handler) *Route {
+	return g.Handle(method, path, i18n,
+		func(w http.ResponseWriter, req *http.Request) {
+			handler.ServeHTTP(w, req)
+		},
+	)
+}
+
+// HandlerFunc is an adapter which allows the usage of an http.HandlerFunc as a
+// request handle of the group.
+func (g *Group) HandlerFunc(method, path string, i18n bool, handler http.HandlerFunc) *Route {
+	return g.Handler(method, path, i18n, handler)
+}
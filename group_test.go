@@ -0,0 +1,61 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	router := New()
+	account := router.Group("/account/")
+	account.RateLimit = NewRateLimiter(1, 1, nil)
+	admin := account.Group("/admin")
+
+	var routed string
+	handle := func(w http.ResponseWriter, r *http.Request) {
+		routed = r.URL.Path
+	}
+	rt := account.GET("/profile", false, handle)
+	if rt.Path() != "/account/profile" || rt.Group() != account {
+		t.Fatalf("wrong route %v %v", rt.Path(), rt.Group())
+	}
+	own := admin.POST("/users", false, handle)
+	own.RateLimit = NewRateLimiter(1, 5, nil)
+
+	if admin.Prefix() != "/account/admin" {
+		t.Fatalf("wrong prefix %v", admin.Prefix())
+	}
+	if account.GET("/x", false, handle).rateLimiter() != account.RateLimit {
+		t.Fatal("route doesn't use the limiter of the group")
+	}
+	if admin.GET("/x", false, handle).rateLimiter() != account.RateLimit {
+		t.Fatal("route doesn't use the limiter of the parent group")
+	}
+	if own.rateLimiter() != own.RateLimit {
+		t.Fatal("route doesn't use its own limiter")
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/account/profile", nil)
+	router.ServeHTTP(w, r)
+	if routed != "/account/profile" {
+		t.Fatalf("routing failed: %q", routed)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("group limiter wasn't applied: %v", w.Code)
+	}
+
+	recv := catchPanic(func() {
+		router.Group("account")
+	})
+	if recv == nil {
+		t.Fatal("prefix without leading slash was accepted")
+	}
+}
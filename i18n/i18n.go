@@ -0,0 +1,197 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+// Package i18n translates the messages of the handles to the language the
+// router negotiated with the client, see httprouter.ContentLang.
+//
+// The messages are loaded from catalogs, one for each language in the
+// SupportedLangs of the router, in JSON or in the gettext PO format:
+//
+//  bundle := i18n.NewBundle(router)
+//  err := bundle.LoadDir("locales") // locales/en.json, locales/pt.po, ...
+//  i18n.Default = bundle
+//
+// and translated in the handles of the i18n routes:
+//
+//  func Cart(w http.ResponseWriter, r *http.Request) {
+//      fmt.Fprint(w, i18n.T(r, "%d items in the cart", n))
+//  }
+//
+// The arguments are interpolated in the message with the fmt verbs, the
+// messages that don't use all the arguments must use explicit argument
+// indexes, like %[2]s. The first integer argument is the count that selects
+// the plural form of the message, see PluralRules.
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fcavani/e"
+	"github.com/fcavani/httprouter"
+)
+
+// Default is the bundle used by T.
+var Default *Bundle
+
+// T translates the message key to the language of the request with the
+// Default bundle. If Default is nil the key is used as the message.
+func T(req *http.Request, key string, args ...interface{}) string {
+	if Default == nil {
+		return format(key, args)
+	}
+	return Default.T(req, key, args...)
+}
+
+// message is a message of a catalog.
+type message struct {
+	text string
+	// plural are the plural forms by category, nil if the message has no
+	// plural forms.
+	plural map[string]string
+}
+
+// get returns the text of the message in the language lang, the plural
+// form is selected by the count in args.
+func (m *message) get(lang string, args []interface{}) string {
+	if m.plural == nil {
+		return m.text
+	}
+	if n, ok := count(args); ok {
+		if text, found := m.plural[pluralRule(lang).Category(n)]; found {
+			return text
+		}
+	}
+	if text, found := m.plural[Other]; found {
+		return text
+	}
+	return m.text
+}
+
+// Bundle is a set of message catalogs, one for each language of a router.
+// The catalogs must be loaded before the router starts serving requests.
+type Bundle struct {
+	router   *httprouter.Router
+	catalogs map[string]map[string]*message
+}
+
+// NewBundle creates an empty bundle for the languages of router.
+func NewBundle(router *httprouter.Router) *Bundle {
+	return &Bundle{
+		router:   router,
+		catalogs: make(map[string]map[string]*message),
+	}
+}
+
+// LoadDir loads the catalogs of the supported languages in the directory
+// dir. The catalog of a language is the file with the name of the language
+// and the extension of its format, .json or .po, for example pt-BR.po. The
+// languages without catalog fall back to other languages, see Translate.
+func (b *Bundle) LoadDir(dir string) error {
+	formats := []struct {
+		ext  string
+		load func(string, io.Reader) error
+	}{
+		{".json", b.LoadJSON},
+		{".po", b.LoadPO},
+	}
+	for lang := range b.router.SupportedLangs {
+		for _, format := range formats {
+			name := filepath.Join(dir, lang+format.ext)
+			f, err := os.Open(name)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return e.Forward(err)
+			}
+			err = format.load(lang, f)
+			f.Close()
+			if err != nil {
+				return e.Push(err, e.New("can't load the catalog %v", name))
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of messages of the language lang.
+func (b *Bundle) Len(lang string) int {
+	return len(b.catalogs[lang])
+}
+
+// add adds the message key to the catalog of the language lang.
+func (b *Bundle) add(lang, key string, m *message) {
+	c, found := b.catalogs[lang]
+	if !found {
+		c = make(map[string]*message)
+		b.catalogs[lang] = c
+	}
+	c[key] = m
+}
+
+// T translates the message key to the language of the request, see
+// Translate.
+func (b *Bundle) T(req *http.Request, key string, args ...interface{}) string {
+	return b.Translate(httprouter.ContentLang(req), key, args...)
+}
+
+// Translate translates the message key to the language lang and
+// interpolates args in it. If the catalog of lang hasn't the message, the
+// catalogs of the parent languages, of the other languages of the same
+// family and of the default language are tried, pt-BR falls back to pt,
+// pt-PT and then to the default language. If no catalog has the message
+// the key is used.
+func (b *Bundle) Translate(lang, key string, args ...interface{}) string {
+	for _, l := range b.fallbacks(lang) {
+		if m, found := b.catalogs[l][key]; found {
+			return format(m.get(l, args), args)
+		}
+	}
+	return format(key, args)
+}
+
+// fallbacks returns the languages tried to translate a message to lang.
+func (b *Bundle) fallbacks(lang string) []string {
+	if lang == "" {
+		lang = b.router.DefaultLang
+	}
+	langs := parents(lang)
+	primary := langs[len(langs)-1] + "-"
+	var family []string
+	for l := range b.catalogs {
+		if len(l) > len(primary) && strings.EqualFold(l[:len(primary)], primary) && !strings.EqualFold(l, lang) {
+			family = append(family, l)
+		}
+	}
+	sort.Strings(family)
+	langs = append(langs, family...)
+	return append(langs, parents(b.router.DefaultLang)...)
+}
+
+// parents returns lang and its parent languages, the subtags at the end of
+// the tag are removed one by one.
+func parents(lang string) []string {
+	langs := []string{lang}
+	for {
+		i := strings.LastIndexByte(lang, '-')
+		if i <= 0 {
+			return langs
+		}
+		lang = lang[:i]
+		langs = append(langs, lang)
+	}
+}
+
+// format interpolates args in text.
+func format(text string, args []interface{}) string {
+	if len(args) == 0 || !strings.Contains(text, "%") {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
@@ -0,0 +1,161 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package i18n
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fcavani/httprouter"
+)
+
+func testRouter() *httprouter.Router {
+	router := httprouter.New()
+	router.DefaultLang = "en"
+	router.SupportedLangs = map[string]struct{}{
+		"en":    struct{}{},
+		"pt-BR": struct{}{},
+		"pt-PT": struct{}{},
+		"es":    struct{}{},
+	}
+	return router
+}
+
+const (
+	enCatalog = `{
+		"hello": "Hello, %s!",
+		"bye": "Bye!",
+		"items": {"one": "%d item", "other": "%d items"}
+	}`
+	ptCatalog = `{
+		"hello": "Olá, %s!",
+		"items": {"one": "%d item", "other": "%d itens"}
+	}`
+	esCatalog = `{
+		"items": {"one": "un artículo", "many": "%d de artículos", "other": "%d artículos"}
+	}`
+)
+
+func testBundle(t *testing.T) *Bundle {
+	b := NewBundle(testRouter())
+	for lang, catalog := range map[string]string{"en": enCatalog, "pt-PT": ptCatalog, "es": esCatalog} {
+		if err := b.LoadJSON(lang, strings.NewReader(catalog)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b
+}
+
+func TestTranslate(t *testing.T) {
+	b := testBundle(t)
+	tests := []struct {
+		lang, key string
+		args      []interface{}
+		want      string
+	}{
+		{"en", "hello", []interface{}{"Gopher"}, "Hello, Gopher!"},
+		{"pt-PT", "hello", []interface{}{"Gopher"}, "Olá, Gopher!"},
+		{"pt-BR", "hello", []interface{}{"Gopher"}, "Olá, Gopher!"},
+		{"pt-BR", "bye", nil, "Bye!"},
+		{"es", "hello", []interface{}{"Gopher"}, "Hello, Gopher!"},
+		{"", "bye", nil, "Bye!"},
+		{"en", "items", []interface{}{1}, "1 item"},
+		{"en", "items", []interface{}{0}, "0 items"},
+		{"en", "items", []interface{}{int64(-1)}, "-1 item"},
+		{"pt-PT", "items", []interface{}{2}, "2 itens"},
+		{"es", "items", []interface{}{1}, "un artículo"},
+		{"es", "items", []interface{}{1000000}, "1000000 de artículos"},
+		{"es", "items", []interface{}{7}, "7 artículos"},
+		{"es", "items", nil, "%d artículos"},
+		{"en", "missing %v", []interface{}{1}, "missing 1"},
+		{"en", "missing", nil, "missing"},
+	}
+	for _, test := range tests {
+		if s := b.Translate(test.lang, test.key, test.args...); s != test.want {
+			t.Errorf("%v %v: wrong translation %q", test.lang, test.key, s)
+		}
+	}
+}
+
+func TestLoadJSONErrors(t *testing.T) {
+	for _, catalog := range []string{
+		`[]`,
+		`{"a": 1}`,
+		`{"a": {"one": "a"}}`,
+		`{"a": {"single": "a", "other": "as"}}`,
+	} {
+		if err := NewBundle(testRouter()).LoadJSON("en", strings.NewReader(catalog)); err == nil {
+			t.Errorf("%v: no error", catalog)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "i18n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"en.json": enCatalog,
+		"pt-BR.po": `msgid "hello"
+msgstr "Oi, %s!"
+`,
+		"de.json": `{"hello": "Hallo, %s!"}`,
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := NewBundle(testRouter())
+	if err = b.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len("en") != 3 || b.Len("pt-BR") != 1 || b.Len("de") != 0 {
+		t.Fatalf("wrong catalogs %v", b.catalogs)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "es.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = NewBundle(testRouter()).LoadDir(dir); err == nil {
+		t.Fatal("invalid catalog loaded")
+	}
+}
+
+func TestT(t *testing.T) {
+	router := testRouter()
+	Default = testBundle(t)
+	defer func() { Default = nil }()
+	var s string
+	router.GET("/hello/:name", true, func(w http.ResponseWriter, r *http.Request) {
+		s = T(r, "hello", httprouter.Parameters(r).ByName("name"))
+	})
+	for url, want := range map[string]string{
+		"/en/hello/Gopher":    "Hello, Gopher!",
+		"/pt-PT/hello/Gopher": "Olá, Gopher!",
+		"/pt-BR/hello/Gopher": "Olá, Gopher!",
+	} {
+		s = ""
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(w, r)
+		if s != want {
+			t.Errorf("%v: wrong translation %q", url, s)
+		}
+	}
+
+	Default = nil
+	r, _ := http.NewRequest("GET", "/", nil)
+	if s = T(r, "hello %v", 1); s != "hello 1" {
+		t.Fatalf("wrong message without bundle %q", s)
+	}
+}
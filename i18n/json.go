@@ -0,0 +1,49 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package i18n
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/fcavani/e"
+)
+
+// LoadJSON loads the catalog of the language lang from a JSON object that
+// maps the keys to the messages. The messages with plural forms are objects
+// that map the plural categories to the forms, the other form is required:
+//  {
+//      "Hello, %s!": "Olá, %s!",
+//      "%d items in the cart": {"one": "%d item no carrinho", "other": "%d itens no carrinho"}
+//  }
+func (b *Bundle) LoadJSON(lang string, r io.Reader) error {
+	var catalog map[string]json.RawMessage
+	err := json.NewDecoder(r).Decode(&catalog)
+	if err != nil {
+		return e.Push(err, "can't decode the catalog")
+	}
+	for key, raw := range catalog {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			b.add(lang, key, &message{text: text})
+			continue
+		}
+		var plural map[string]string
+		err = json.Unmarshal(raw, &plural)
+		if err != nil {
+			return e.New("message %v isn't a string or an object of plural forms", key)
+		}
+		for category := range plural {
+			if !validCategory(category) {
+				return e.New("invalid plural category %v in message %v", category, key)
+			}
+		}
+		if _, found := plural[Other]; !found {
+			return e.New("message %v has no %v plural form", key, Other)
+		}
+		b.add(lang, key, &message{plural: plural})
+	}
+	return nil
+}
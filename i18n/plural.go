@@ -0,0 +1,245 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package i18n
+
+import "strings"
+
+// The CLDR plural categories.
+const (
+	Zero  = "zero"
+	One   = "one"
+	Two   = "two"
+	Few   = "few"
+	Many  = "many"
+	Other = "other"
+)
+
+// PluralRule is the CLDR plural rule of a language for the integers.
+type PluralRule struct {
+	// Categories are the categories of the integers in the order of the
+	// plural forms of the gettext catalogs.
+	Categories []string
+	// Category returns the category of the integer n.
+	Category func(n uint64) string
+}
+
+var (
+	otherRule = &PluralRule{
+		Categories: []string{Other},
+		Category:   func(n uint64) string { return Other },
+	}
+	oneRule = &PluralRule{
+		Categories: []string{One, Other},
+		Category: func(n uint64) string {
+			if n == 1 {
+				return One
+			}
+			return Other
+		},
+	}
+	zeroOneRule = &PluralRule{
+		Categories: []string{One, Other},
+		Category: func(n uint64) string {
+			if n <= 1 {
+				return One
+			}
+			return Other
+		},
+	}
+	// The romance languages use many for the millions: un million d'euros.
+	romanceRule = &PluralRule{
+		Categories: []string{One, Many, Other},
+		Category: func(n uint64) string {
+			switch {
+			case n == 1:
+				return One
+			case n != 0 && n%1000000 == 0:
+				return Many
+			}
+			return Other
+		},
+	}
+	zeroOneRomanceRule = &PluralRule{
+		Categories: []string{One, Many, Other},
+		Category: func(n uint64) string {
+			switch {
+			case n <= 1:
+				return One
+			case n%1000000 == 0:
+				return Many
+			}
+			return Other
+		},
+	}
+	slavicRule = &PluralRule{
+		Categories: []string{One, Few, Many},
+		Category: func(n uint64) string {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return One
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return Few
+			}
+			return Many
+		},
+	}
+	polishRule = &PluralRule{
+		Categories: []string{One, Few, Many},
+		Category: func(n uint64) string {
+			switch {
+			case n == 1:
+				return One
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return Few
+			}
+			return Many
+		},
+	}
+	czechRule = &PluralRule{
+		Categories: []string{One, Few, Other},
+		Category: func(n uint64) string {
+			switch {
+			case n == 1:
+				return One
+			case n >= 2 && n <= 4:
+				return Few
+			}
+			return Other
+		},
+	}
+	hebrewRule = &PluralRule{
+		Categories: []string{One, Two, Other},
+		Category: func(n uint64) string {
+			switch n {
+			case 1:
+				return One
+			case 2:
+				return Two
+			}
+			return Other
+		},
+	}
+	arabicRule = &PluralRule{
+		Categories: []string{Zero, One, Two, Few, Many, Other},
+		Category: func(n uint64) string {
+			switch {
+			case n == 0:
+				return Zero
+			case n == 1:
+				return One
+			case n == 2:
+				return Two
+			case n%100 >= 3 && n%100 <= 10:
+				return Few
+			case n%100 >= 11:
+				return Many
+			}
+			return Other
+		},
+	}
+)
+
+// PluralRules are the plural rules by language. The rule of a language
+// without one is the rule of its parent language, pt-BR uses the rule of
+// pt, or the rule of English. More rules may be added before the catalogs
+// are loaded.
+var PluralRules = map[string]*PluralRule{
+	"ar":    arabicRule,
+	"be":    slavicRule,
+	"bg":    oneRule,
+	"ca":    romanceRule,
+	"cs":    czechRule,
+	"da":    oneRule,
+	"de":    oneRule,
+	"el":    oneRule,
+	"en":    oneRule,
+	"es":    romanceRule,
+	"et":    oneRule,
+	"eu":    oneRule,
+	"fi":    oneRule,
+	"fr":    zeroOneRomanceRule,
+	"gl":    oneRule,
+	"he":    hebrewRule,
+	"hi":    zeroOneRule,
+	"hu":    oneRule,
+	"id":    otherRule,
+	"it":    romanceRule,
+	"ja":    otherRule,
+	"ko":    otherRule,
+	"ms":    otherRule,
+	"nb":    oneRule,
+	"nl":    oneRule,
+	"nn":    oneRule,
+	"no":    oneRule,
+	"pl":    polishRule,
+	"pt":    zeroOneRomanceRule,
+	"pt-PT": romanceRule,
+	"ru":    slavicRule,
+	"sk":    czechRule,
+	"sv":    oneRule,
+	"th":    otherRule,
+	"tr":    oneRule,
+	"uk":    slavicRule,
+	"vi":    otherRule,
+	"zh":    otherRule,
+}
+
+// pluralRule returns the plural rule of the language lang.
+func pluralRule(lang string) *PluralRule {
+	for _, l := range parents(lang) {
+		if rule, found := PluralRules[l]; found {
+			return rule
+		}
+		if rule, found := PluralRules[strings.ToLower(l)]; found {
+			return rule
+		}
+	}
+	return oneRule
+}
+
+// validCategory returns true if c is a CLDR plural category.
+func validCategory(c string) bool {
+	switch c {
+	case Zero, One, Two, Few, Many, Other:
+		return true
+	}
+	return false
+}
+
+// count returns the first integer in args, the absolute value.
+func count(args []interface{}) (uint64, bool) {
+	for _, arg := range args {
+		var n int64
+		switch v := arg.(type) {
+		case int:
+			n = int64(v)
+		case int8:
+			n = int64(v)
+		case int16:
+			n = int64(v)
+		case int32:
+			n = int64(v)
+		case int64:
+			n = v
+		case uint:
+			return uint64(v), true
+		case uint8:
+			return uint64(v), true
+		case uint16:
+			return uint64(v), true
+		case uint32:
+			return uint64(v), true
+		case uint64:
+			return v, true
+		default:
+			continue
+		}
+		if n < 0 {
+			return uint64(-n), true
+		}
+		return uint64(n), true
+	}
+	return 0, false
+}
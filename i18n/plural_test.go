@@ -0,0 +1,55 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package i18n
+
+import "testing"
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		lang  string
+		cases map[uint64]string
+	}{
+		{"en", map[uint64]string{0: Other, 1: One, 2: Other, 11: Other}},
+		{"en-GB", map[uint64]string{1: One, 21: Other}},
+		{"xx", map[uint64]string{1: One, 2: Other}},
+		{"ja", map[uint64]string{0: Other, 1: Other}},
+		{"fr", map[uint64]string{0: One, 1: One, 2: Other, 1000000: Many}},
+		{"pt-BR", map[uint64]string{0: One, 1: One, 2: Other}},
+		{"pt-PT", map[uint64]string{0: Other, 1: One, 2000000: Many}},
+		{"es", map[uint64]string{0: Other, 1: One, 5: Other, 1000000: Many}},
+		{"ru", map[uint64]string{1: One, 21: One, 11: Many, 2: Few, 22: Few, 12: Many, 5: Many, 0: Many}},
+		{"pl", map[uint64]string{1: One, 21: Many, 2: Few, 14: Many, 24: Few}},
+		{"cs", map[uint64]string{1: One, 3: Few, 5: Other}},
+		{"he", map[uint64]string{1: One, 2: Two, 3: Other}},
+		{"ar", map[uint64]string{0: Zero, 1: One, 2: Two, 3: Few, 110: Few, 11: Many, 99: Many, 100: Other, 102: Other}},
+	}
+	for _, test := range tests {
+		rule := pluralRule(test.lang)
+		for n, want := range test.cases {
+			if c := rule.Category(n); c != want {
+				t.Errorf("%v %v: wrong category %v", test.lang, n, c)
+			}
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		args []interface{}
+		n    uint64
+		ok   bool
+	}{
+		{nil, 0, false},
+		{[]interface{}{"a", 1.5}, 0, false},
+		{[]interface{}{"a", 3, 4}, 3, true},
+		{[]interface{}{int8(-2)}, 2, true},
+		{[]interface{}{uint32(7)}, 7, true},
+	}
+	for _, test := range tests {
+		if n, ok := count(test.args); n != test.n || ok != test.ok {
+			t.Errorf("%v: wrong count %v %v", test.args, n, ok)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package i18n
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fcavani/e"
+)
+
+// poEntry is an entry of a PO file.
+type poEntry struct {
+	ctxt     string
+	id       string
+	idPlural string
+	strs     []string
+	fuzzy    bool
+}
+
+// key returns the key of the message of the entry. The key of an entry with
+// context is the context and the msgid separated by \x04, like in gettext.
+func (pe *poEntry) key() string {
+	if pe.ctxt != "" {
+		return pe.ctxt + "\x04" + pe.id
+	}
+	return pe.id
+}
+
+// LoadPO loads the catalog of the language lang from a gettext PO file. The
+// msgid of the entries are the keys. The header, the fuzzy entries and the
+// untranslated ones are skipped. The plural forms, msgstr[n], are mapped to
+// the categories of the plural rule of lang in order, see
+// PluralRule.Categories, and the last one is the other form too. The
+// Plural-Forms formula of the header isn't used.
+func (b *Bundle) LoadPO(lang string, r io.Reader) error {
+	entries, err := parsePO(r)
+	if err != nil {
+		return e.Forward(err)
+	}
+	categories := pluralRule(lang).Categories
+	for _, pe := range entries {
+		if pe.fuzzy || pe.id == "" || len(pe.strs) == 0 {
+			continue
+		}
+		if pe.idPlural == "" {
+			if pe.strs[0] != "" {
+				b.add(lang, pe.key(), &message{text: pe.strs[0]})
+			}
+			continue
+		}
+		plural := make(map[string]string, len(pe.strs))
+		for i, str := range pe.strs {
+			if str == "" || i >= len(categories) {
+				continue
+			}
+			plural[categories[i]] = str
+		}
+		if _, found := plural[Other]; !found {
+			if last := pe.strs[len(pe.strs)-1]; last != "" {
+				plural[Other] = last
+			}
+		}
+		if len(plural) > 0 {
+			b.add(lang, pe.key(), &message{plural: plural})
+		}
+	}
+	return nil
+}
+
+// parsePO reads the entries of a PO file.
+func parsePO(r io.Reader) ([]*poEntry, error) {
+	var entries []*poEntry
+	pe := &poEntry{}
+	// field is the string continued by the lines with only a string.
+	var field *string
+	flush := func() {
+		entries = append(entries, pe)
+		pe = &poEntry{}
+		field = nil
+	}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line[0] == '#':
+			if len(pe.strs) > 0 {
+				flush()
+			}
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				pe.fuzzy = true
+			}
+			continue
+		case line[0] == '"':
+			if field == nil {
+				return nil, e.New("line %v: string without keyword", n)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, e.New("line %v: invalid string %v", n, line)
+			}
+			*field += s
+			continue
+		}
+		i := strings.IndexByte(line, ' ')
+		if i < 0 {
+			return nil, e.New("line %v: invalid line %v", n, line)
+		}
+		keyword := line[:i]
+		s, err := strconv.Unquote(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, e.New("line %v: invalid string %v", n, line[i+1:])
+		}
+		if (keyword == "msgctxt" || keyword == "msgid") && len(pe.strs) > 0 {
+			flush()
+		}
+		switch {
+		case keyword == "msgctxt":
+			pe.ctxt = s
+			field = &pe.ctxt
+		case keyword == "msgid":
+			pe.id = s
+			field = &pe.id
+		case keyword == "msgid_plural":
+			pe.idPlural = s
+			field = &pe.idPlural
+		case keyword == "msgstr" || keyword == "msgstr["+strconv.Itoa(len(pe.strs))+"]":
+			pe.strs = append(pe.strs, s)
+			field = &pe.strs[len(pe.strs)-1]
+		default:
+			return nil, e.New("line %v: unexpected keyword %v", n, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, e.Forward(err)
+	}
+	if len(pe.strs) > 0 {
+		flush()
+	}
+	return entries, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+const ruPO = `# Russian translation.
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#: cart.go:10
+msgid "hello"
+msgstr "Привет, "
+"%s!"
+
+msgid "%d items"
+msgid_plural "%d items"
+msgstr[0] "%d товар"
+msgstr[1] "%d товара"
+msgstr[2] "%d товаров"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Открыть"
+
+#, fuzzy
+msgid "bye"
+msgstr "Пока"
+
+msgid "untranslated"
+msgstr ""
+`
+
+func TestLoadPO(t *testing.T) {
+	b := NewBundle(testRouter())
+	if err := b.LoadPO("ru", strings.NewReader(ruPO)); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len("ru") != 3 {
+		t.Fatalf("wrong number of messages %v", b.Len("ru"))
+	}
+	tests := []struct {
+		key  string
+		args []interface{}
+		want string
+	}{
+		{"hello", []interface{}{"Gopher"}, "Привет, Gopher!"},
+		{"%d items", []interface{}{1}, "1 товар"},
+		{"%d items", []interface{}{3}, "3 товара"},
+		{"%d items", []interface{}{5}, "5 товаров"},
+		{"%d items", nil, "%d товаров"},
+		{"menu\x04Open", nil, "Открыть"},
+		{"bye", nil, "bye"},
+		{"untranslated", nil, "untranslated"},
+	}
+	for _, test := range tests {
+		if s := b.Translate("ru", test.key, test.args...); s != test.want {
+			t.Errorf("%q: wrong translation %q", test.key, s)
+		}
+	}
+}
+
+func TestLoadPOPluralForms(t *testing.T) {
+	// Two forms for a language with the many category.
+	po := `msgid "%d item"
+msgid_plural "%d items"
+msgstr[0] "%d artículo"
+msgstr[1] "%d artículos"
+`
+	b := NewBundle(testRouter())
+	if err := b.LoadPO("es", strings.NewReader(po)); err != nil {
+		t.Fatal(err)
+	}
+	for n, want := range map[int]string{1: "1 artículo", 2: "2 artículos", 1000000: "1000000 artículos"} {
+		if s := b.Translate("es", "%d item", n); s != want {
+			t.Errorf("%v: wrong translation %q", n, s)
+		}
+	}
+}
+
+func TestLoadPOErrors(t *testing.T) {
+	for _, po := range []string{
+		`"orphan"`,
+		`msgid hello`,
+		`msgid`,
+		"msgid \"a\"\nmsgstr[1] \"b\"",
+		"msgid \"a\"\nmsgtxt \"b\"",
+	} {
+		if err := NewBundle(testRouter()).LoadPO("en", strings.NewReader(po)); err == nil {
+			t.Errorf("%q: no error", po)
+		}
+	}
+}
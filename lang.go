@@ -0,0 +1,162 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// findLang returns the language in langs equal to tag, ignoring the case.
+func findLang(langs map[string]struct{}, tag string) string {
+	if _, found := langs[tag]; found {
+		return tag
+	}
+	for lang := range langs {
+		if strings.EqualFold(lang, tag) {
+			return lang
+		}
+	}
+	return ""
+}
+
+// matchLang returns the supported language for the language tag, or an
+// empty string if there is none, see matchLangIn.
+func (r *Router) matchLang(tag string) string {
+	return r.matchLangIn(r.SupportedLangs, tag)
+}
+
+// matchLangIn returns the language in langs for the language tag, or an
+// empty string if there is none. As in the RFC 4647 lookup, the subtags at
+// the end of the tag are removed until a language is found, pt-BR falls
+// back to pt. At each step the fallback chain of the tag in LangFallback is
+// tried. If nothing is found the other languages of the same family are
+// used, pt-BR falls back to pt-PT.
+func (r *Router) matchLangIn(langs map[string]struct{}, tag string) string {
+	if tag == "" || tag == "*" {
+		return ""
+	}
+	primary := tag
+	for {
+		if lang := findLang(langs, primary); lang != "" {
+			return lang
+		}
+		if lang := r.fallbackLang(langs, primary); lang != "" {
+			return lang
+		}
+		i := strings.LastIndexByte(primary, '-')
+		if i <= 0 {
+			break
+		}
+		primary = primary[:i]
+	}
+	var family []string
+	for lang := range langs {
+		if len(lang) > len(primary) && lang[len(primary)] == '-' && strings.EqualFold(lang[:len(primary)], primary) {
+			family = append(family, lang)
+		}
+	}
+	if len(family) == 0 {
+		return ""
+	}
+	sort.Strings(family)
+	return family[0]
+}
+
+// fallbackLang returns the first language of langs in the fallback chain of
+// tag.
+func (r *Router) fallbackLang(langs map[string]struct{}, tag string) string {
+	for from, chain := range r.LangFallback {
+		if !strings.EqualFold(from, tag) {
+			continue
+		}
+		for _, fb := range chain {
+			if lang := findLang(langs, fb); lang != "" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// availableLang returns the language of the route for lang: lang itself if
+// the route is available in it, or the best language of the route for
+// lang, or the default language if the route is available in it, or the
+// first language of the route.
+func (r *Router) availableLang(rt *Route, lang string) string {
+	if rt.Langs == nil {
+		return lang
+	}
+	langs := rt.langSet()
+	if l := r.matchLangIn(langs, lang); l != "" {
+		return l
+	}
+	if l := findLang(langs, r.DefaultLang); l != "" {
+		return l
+	}
+	if len(rt.Langs) == 0 {
+		return ""
+	}
+	return rt.Langs[0]
+}
+
+// langPrefix is the language in the first segment of a path.
+type langPrefix struct {
+	// tag is the segment as requested.
+	tag string
+	// lang is the supported language matched by tag.
+	lang string
+	// rest is the path without the segment.
+	rest string
+	// pathLang is the language of the localized path of the route matched
+	// by rest, see Route.Localize. It is empty for the path of the route.
+	pathLang string
+}
+
+// splitLang returns the language prefix of path. lang is empty if the first
+// segment isn't a language.
+func (r *Router) splitLang(path string) langPrefix {
+	if len(path) < 2 || path[0] != '/' {
+		return langPrefix{}
+	}
+	lp := langPrefix{tag: path[1:], rest: "/"}
+	if i := strings.IndexByte(lp.tag, '/'); i >= 0 {
+		lp.tag, lp.rest = lp.tag[:i], lp.tag[i:]
+	}
+	lp.lang = r.matchLang(lp.tag)
+	return lp
+}
+
+// getValue looks up the route of path in root. If there is none and the
+// first segment of path is a language, the rest of the path is looked up in
+// the i18n routes. The localized paths resolve to the route they localize.
+func (r *Router) getValue(root *node, path string) (http.HandlerFunc, *Params, *Route, bool, langPrefix) {
+	handle, ps, rt, tsr := root.getValue(path, r.getParams)
+	if handle != nil {
+		if rt.base != nil {
+			return handle, ps, rt.base, tsr, langPrefix{pathLang: rt.lang}
+		}
+		return handle, ps, rt, tsr, langPrefix{}
+	}
+	if r.DefaultLang == "" {
+		return handle, ps, rt, tsr, langPrefix{}
+	}
+	lp := r.splitLang(path)
+	if lp.lang == "" {
+		return handle, ps, rt, tsr, langPrefix{}
+	}
+	r.putParams(ps)
+	handle, ps, rt, ltsr := root.getValue(lp.rest, r.getParams)
+	if handle == nil || !rt.i18n {
+		r.putParams(ps)
+		return nil, nil, nil, tsr || ltsr, langPrefix{}
+	}
+	if rt.base != nil {
+		lp.pathLang = rt.lang
+		rt = rt.base
+	}
+	return handle, ps, rt, tsr, lp
+}
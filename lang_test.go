@@ -0,0 +1,253 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func langRouter() *Router {
+	router := New()
+	router.DefaultLang = "en"
+	router.SupportedLangs = map[string]struct{}{
+		"en":    struct{}{},
+		"pt":    struct{}{},
+		"es-ES": struct{}{},
+		"es-MX": struct{}{},
+	}
+	return router
+}
+
+func TestMatchLang(t *testing.T) {
+	router := langRouter()
+	router.LangFallback = map[string][]string{
+		"gl": {"es-ES", "pt"},
+	}
+	tests := []struct {
+		tag, lang string
+	}{
+		{"en", "en"},
+		{"EN", "en"},
+		{"pt-BR", "pt"},
+		{"pt-br-x-private", "pt"},
+		{"es", "es-ES"},
+		{"es-es", "es-ES"},
+		{"es-AR", "es-ES"},
+		{"gl", "es-ES"},
+		{"gl-ES", "es-ES"},
+		{"de", ""},
+		{"*", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if lang := router.matchLang(test.tag); lang != test.lang {
+			t.Errorf("matchLang(%q) = %q, want %q", test.tag, lang, test.lang)
+		}
+	}
+}
+
+func TestResolveLang(t *testing.T) {
+	router := langRouter()
+	tests := []struct {
+		accept, lang string
+	}{
+		{"", ""},
+		{"pt-BR,pt;q=0.9,en;q=0.8", "pt"},
+		{"de-DE, es-MX;q=0.5", "es-MX"},
+		{"de, fr;q=0.5", ""},
+		{"pt;q=0, en;q=0.1", "en"},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		if test.accept != "" {
+			r.Header.Set("Accept-Language", test.accept)
+		}
+		if lang := router.resolveLang(r); lang != test.lang {
+			t.Errorf("resolveLang(%q) = %q, want %q", test.accept, lang, test.lang)
+		}
+	}
+}
+
+func TestLangPrefixRouting(t *testing.T) {
+	router := langRouter()
+	var served []string
+	router.GET("/lang", true, func(w http.ResponseWriter, r *http.Request) {
+		served = append(served, ContentLang(r))
+	})
+	router.GET("/plain", false, func(w http.ResponseWriter, r *http.Request) {
+		served = append(served, "plain")
+	})
+	router.GET("/", true, func(w http.ResponseWriter, r *http.Request) {
+		served = append(served, "root "+ContentLang(r))
+	})
+
+	tests := []struct {
+		url, accept string
+		code        int
+		location    string
+	}{
+		{"/lang", "pt-BR", http.StatusMovedPermanently, "/pt/lang"},
+		{"/lang", "", http.StatusMovedPermanently, "/en/lang"},
+		{"/pt-BR/lang", "", http.StatusMovedPermanently, "/pt/lang"},
+		{"/es/lang", "", http.StatusMovedPermanently, "/es-ES/lang"},
+		{"/pt/lang", "", http.StatusOK, ""},
+		{"/pt/lang/", "", http.StatusMovedPermanently, "/pt/lang"},
+		{"/es-MX", "", http.StatusOK, ""},
+		{"/en/plain", "", http.StatusNotFound, ""},
+		{"/plain", "", http.StatusOK, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		if test.accept != "" {
+			r.Header.Set("Accept-Language", test.accept)
+		}
+		router.ServeHTTP(w, r)
+		if w.Code != test.code || w.Header().Get("Location") != test.location {
+			t.Errorf("%v: wrong response %v %q", test.url, w.Code, w.Header().Get("Location"))
+		}
+	}
+	if len(served) != 3 || served[0] != "pt" || served[1] != "root es-MX" || served[2] != "plain" {
+		t.Fatalf("wrong handles served: %v", served)
+	}
+
+	if handle, _, _ := router.Lookup("GET", "/en/lang"); handle == nil {
+		t.Fatal("Lookup failed with the language prefix")
+	}
+	if handle, _, _ := router.Lookup("GET", "/en/plain"); handle != nil {
+		t.Fatal("Lookup found a route without i18n under the language prefix")
+	}
+}
+
+func TestContentLanguage(t *testing.T) {
+	router := langRouter()
+	router.LangCookie = &LangCookie{}
+	router.GET("/lang", true, func(w http.ResponseWriter, r *http.Request) {})
+	router.GET("/override", true, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Language", "pt, en")
+	})
+	router.GET("/plain", false, func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		url, lang, vary string
+	}{
+		{"/lang", "", "Cookie, Accept-Language"},
+		{"/pt/lang", "pt", ""},
+		{"/pt/override", "pt, en", ""},
+		{"/plain", "", ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(w, r)
+		if lang := w.Header().Get("Content-Language"); lang != test.lang {
+			t.Errorf("%v: wrong Content-Language %q", test.url, lang)
+		}
+		if vary := strings.Join(w.Header()["Vary"], ", "); vary != test.vary {
+			t.Errorf("%v: wrong Vary %q", test.url, vary)
+		}
+	}
+
+	router.LangStrategy = ServeLang
+	router.LangResolvers = []LangResolver{HeaderLang("x-lang"), QueryLang("lang")}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/lang", nil)
+	r.Header.Set("X-Lang", "pt")
+	router.ServeHTTP(w, r)
+	if w.Header().Get("Content-Language") != "pt" || strings.Join(w.Header()["Vary"], ", ") != "Cookie, X-Lang" {
+		t.Fatalf("wrong headers %v", w.Header())
+	}
+}
+
+func TestRouteLangs(t *testing.T) {
+	router := langRouter()
+	var lang string
+	var alts []Alternate
+	router.GET("/doc", true, func(w http.ResponseWriter, r *http.Request) {
+		lang = ContentLang(r)
+		alts = Alternates(r)
+	}).Langs = []string{"pt", "es-MX"}
+	router.GET("/none", true, func(w http.ResponseWriter, r *http.Request) {}).Langs = []string{}
+
+	tests := []struct {
+		policy   LangUnavailable
+		url      string
+		code     int
+		location string
+		lang     string
+	}{
+		{RedirectAvailableLang, "/pt/doc", http.StatusOK, "", "pt"},
+		{RedirectAvailableLang, "/en/doc", http.StatusMovedPermanently, "/pt/doc", ""},
+		{RedirectAvailableLang, "/es-ES/doc", http.StatusMovedPermanently, "/es-MX/doc", ""},
+		{RedirectAvailableLang, "/doc", http.StatusMovedPermanently, "/pt/doc", ""},
+		{ServeAvailableLang, "/en/doc", http.StatusOK, "", "pt"},
+		{ServeAvailableLang, "/es-ES/doc", http.StatusOK, "", "es-MX"},
+		{NotFoundLang, "/en/doc", http.StatusNotFound, "", ""},
+		{NotFoundLang, "/es-MX/doc", http.StatusOK, "", "es-MX"},
+		{RedirectAvailableLang, "/en/none", http.StatusNotFound, "", ""},
+		{RedirectAvailableLang, "/none", http.StatusNotFound, "", ""},
+	}
+	for _, test := range tests {
+		lang = ""
+		router.LangUnavailable = test.policy
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("%v: wrong code %v", test.url, w.Code)
+		}
+		if loc := w.Header().Get("Location"); loc != test.location {
+			t.Errorf("%v: wrong location %q", test.url, loc)
+		}
+		if lang != test.lang {
+			t.Errorf("%v: wrong language %q", test.url, lang)
+		}
+		if test.code == http.StatusOK && w.Header().Get("Content-Language") != test.lang {
+			t.Errorf("%v: wrong Content-Language %q", test.url, w.Header().Get("Content-Language"))
+		}
+	}
+
+	if len(alts) != 3 || alts[0].Lang != "es-MX" || alts[1].Lang != "pt" || alts[2].Lang != XDefault || alts[2].URL != alts[1].URL {
+		t.Fatalf("wrong alternates %v", alts)
+	}
+}
+
+func TestLangAllowed(t *testing.T) {
+	router := langRouter()
+	router.CORS = &CORS{AllowedOrigins: []string{"https://app.example.com"}}
+	router.GET("/lang", true, func(w http.ResponseWriter, r *http.Request) {})
+	router.GET("/plain", false, func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		method, url string
+		code        int
+		allow       string
+	}{
+		{"POST", "/en/lang", http.StatusMethodNotAllowed, "GET, OPTIONS"},
+		{"POST", "/pt-BR/lang", http.StatusMethodNotAllowed, "GET, OPTIONS"},
+		{"OPTIONS", "/en/lang", http.StatusOK, "GET, OPTIONS"},
+		{"POST", "/en/plain", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(test.method, test.url, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != test.code || w.Header().Get("Allow") != test.allow {
+			t.Errorf("%v %v: wrong response %v %q", test.method, test.url, w.Code, w.Header().Get("Allow"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("OPTIONS", "/en/lang", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("wrong preflight response %v %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"time"
+)
+
+// SameSite is the SameSite attribute of a cookie, like http.SameSite that
+// isn't available before Go 1.11. The attribute is only sent by the builds
+// with Go 1.11 or later, and SameSiteNoneMode by the ones with Go 1.13 or
+// later. The zero value omits the attribute.
+type SameSite int
+
+const (
+	// SameSiteDefaultMode sends the attribute without value.
+	SameSiteDefaultMode SameSite = iota + 1
+	// SameSiteLaxMode sends the cookie in the top level navigations from
+	// other sites, but not in their subrequests.
+	SameSiteLaxMode
+	// SameSiteStrictMode only sends the cookie in the requests from the
+	// same site.
+	SameSiteStrictMode
+	// SameSiteNoneMode sends the cookie in the requests from other sites
+	// too. The browsers require a Secure cookie with it.
+	SameSiteNoneMode
+)
+
+// LangCookie configures the cookie that remembers the language chosen by the
+// client, see Router.LangCookie.
+type LangCookie struct {
+	// Name of the cookie, "lang" if empty.
+	Name string
+	// Path of the cookie, "/" if empty.
+	Path   string
+	Domain string
+	// For how long the cookie is kept. Zero makes a session cookie.
+	MaxAge   time.Duration
+	Secure   bool
+	SameSite SameSite
+}
+
+func (c *LangCookie) name() string {
+	if c.Name == "" {
+		return "lang"
+	}
+	return c.Name
+}
+
+// set sets the cookie with lang, if the request doesn't have it already.
+func (c *LangCookie) set(w http.ResponseWriter, req *http.Request, lang string) {
+	name := c.name()
+	if cur, err := req.Cookie(name); err == nil && cur.Value == lang {
+		return
+	}
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    lang,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Secure:   c.Secure,
+		HttpOnly: true,
+	}
+	setSameSite(cookie, c.SameSite)
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if c.MaxAge > 0 {
+		cookie.MaxAge = int(c.MaxAge / time.Second)
+		cookie.Expires = time.Now().Add(c.MaxAge)
+	}
+	http.SetCookie(w, cookie)
+}
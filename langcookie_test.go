@@ -0,0 +1,88 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLangCookie(t *testing.T) {
+	router := langRouter()
+	router.LangCookie = &LangCookie{
+		MaxAge:   24 * time.Hour,
+		Domain:   "example.com",
+		Secure:   true,
+		SameSite: SameSiteLaxMode,
+	}
+	var served string
+	router.GET("/lang", true, func(w http.ResponseWriter, r *http.Request) {
+		served = ContentLang(r)
+	})
+
+	serve := func(url, cookie string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", url, nil)
+		r.Header.Set("Accept-Language", "en")
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+	cookie := func(w *httptest.ResponseRecorder) *http.Cookie {
+		for _, c := range w.Result().Cookies() {
+			if c.Name == "lang" {
+				return c
+			}
+		}
+		return nil
+	}
+
+	// The user picks Portuguese on an English browser.
+	w := serve("/pt/lang", "")
+	c := cookie(w)
+	if served != "pt" || c == nil || c.Value != "pt" {
+		t.Fatalf("cookie wasn't set: %v", c)
+	}
+	if c.MaxAge != 86400 || c.Domain != "example.com" || !c.Secure || !c.HttpOnly ||
+		!hasSameSite(c, SameSiteLaxMode) || c.Path != "/" {
+		t.Fatalf("wrong cookie %#v", c)
+	}
+
+	// Bare links keep Portuguese.
+	w = serve("/lang", "pt")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/pt/lang" {
+		t.Fatalf("wrong redirect %v %v", w.Code, w.Header().Get("Location"))
+	}
+	if cookie(w) != nil {
+		t.Fatal("unchanged cookie was sent again")
+	}
+
+	w = serve("/lang", "")
+	if w.Header().Get("Location") != "/en/lang" || cookie(w) == nil || cookie(w).Value != "en" {
+		t.Fatalf("redirect didn't set the cookie: %v", w.Header())
+	}
+
+	w = serve("/es-MX/lang", "pt")
+	if served != "es-MX" || cookie(w) == nil || cookie(w).Value != "es-MX" {
+		t.Fatalf("visiting a prefix didn't change the cookie: %v", w.Header())
+	}
+}
+
+func TestLangCookieSameSiteNone(t *testing.T) {
+	router := langRouter()
+	router.LangCookie = &LangCookie{Secure: true, SameSite: SameSiteNoneMode}
+	router.GET("/lang", true, func(w http.ResponseWriter, r *http.Request) {})
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/pt/lang", nil)
+	router.ServeHTTP(w, r)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !hasSameSite(cookies[0], SameSiteNoneMode) {
+		t.Fatalf("wrong cookie %v", w.Header()["Set-Cookie"])
+	}
+}
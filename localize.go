@@ -0,0 +1,100 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import "strings"
+
+// Localize registers path as the path of the i18n route in the language
+// lang. The path is without the language prefix, like the path of
+// the route:
+//  router.GET("/products/:id", true, product).
+//      Localize("pt", "/produtos/:id").
+//      Localize("de", "/produkte/:id")
+// All paths reach the handle of the route. The languages without a localized
+// path, or whose parent language has none, use the path of the route. A
+// request whose path isn't the one of its language is redirected to it, like
+// /pt/products/1 is redirected to /pt/produtos/1. The path must have the
+// same parameters of the path of the route. Localize returns the route.
+func (rt *Route) Localize(lang, path string) *Route {
+	if !rt.i18n {
+		panic("only i18n routes can be localized in path '" + rt.path + "'")
+	}
+	if lang == "" {
+		panic("language must not be empty in path '" + path + "'")
+	}
+	if _, found := rt.paths[lang]; found {
+		panic("route '" + rt.path + "' already localized in '" + lang + "'")
+	}
+	if strings.Join(paramNames(path), ",") != strings.Join(paramNames(rt.path), ",") {
+		panic("path '" + path + "' must have the parameters of '" + rt.path + "'")
+	}
+	local := rt.router.Handle(rt.method, path, true, rt.handle)
+	local.base = rt
+	local.lang = lang
+	if rt.paths == nil {
+		rt.paths = make(map[string]string)
+	}
+	rt.paths[lang] = path
+	return rt
+}
+
+// LocalPath returns the path of the route in the language lang, with its
+// language prefix and the values of the parameters ps. This is the reverse
+// of the routing:
+//  rt.LocalPath("pt", Params{{"id", "42"}}) // "/pt/produtos/42"
+// If lang is empty, the path of the route is returned without prefix. The
+// values of the parameters are escaped.
+func (rt *Route) LocalPath(lang string, ps Params) string {
+	if lang == "" {
+		return expandTemplate(rt.path, escapeParams(rt.path, ps))
+	}
+	pattern := rt.pattern(lang)
+	return "/" + lang + expandTemplate(pattern, escapeParams(pattern, ps))
+}
+
+// pattern returns the path of the route in the language lang. The subtags
+// at the end of lang are removed until a localized path is found, pt-BR uses
+// the path of pt.
+func (rt *Route) pattern(lang string) string {
+	for lang != "" {
+		if path, found := rt.paths[lang]; found {
+			return path
+		}
+		i := strings.LastIndexByte(lang, '-')
+		if i <= 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return rt.path
+}
+
+// localPath returns rest, the path without the language prefix that matched
+// the path of the route in the language from, in the path of the language
+// lang.
+func (rt *Route) localPath(lang, from, rest string, ps Params) string {
+	pattern := rt.pattern(lang)
+	if pattern == rt.pattern(from) {
+		return rest
+	}
+	return expandTemplate(pattern, ps)
+}
+
+// paramNames returns the names of the parameters in path.
+func paramNames(path string) []string {
+	var names []string
+	for i := 0; i < len(path); i++ {
+		if path[i] != ':' && path[i] != '*' {
+			continue
+		}
+		end := i + 1
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
+		names = append(names, path[i+1:end])
+		i = end
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	router := langRouter()
+	router.SupportedLangs["de"] = struct{}{}
+	var id, lang string
+	var alts []Alternate
+	rt := router.GET("/products/:id", true, func(w http.ResponseWriter, r *http.Request) {
+		id = Parameters(r).ByName("id")
+		lang = ContentLang(r)
+		alts = Alternates(r)
+	}).Localize("pt", "/produtos/:id").Localize("de", "/produkte/:id")
+
+	tests := []struct {
+		url, location, lang string
+	}{
+		{"/en/products/42", "", "en"},
+		{"/pt/produtos/42", "", "pt"},
+		{"/de/produkte/42", "", "de"},
+		{"/es-ES/products/42", "", "es-ES"},
+		{"/pt/products/42", "/pt/produtos/42", ""},
+		{"/en/produkte/42", "/en/products/42", ""},
+		{"/pt-BR/produkte/42", "/pt/produtos/42", ""},
+		{"/produtos/42", "/en/products/42", ""},
+	}
+	for _, test := range tests {
+		id, lang = "", ""
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(w, r)
+		if loc := w.Header().Get("Location"); loc != test.location {
+			t.Errorf("%v: wrong location %q", test.url, loc)
+		}
+		if test.location == "" && (w.Code != http.StatusOK || id != "42") {
+			t.Errorf("%v: not served, code %v", test.url, w.Code)
+		}
+		if lang != test.lang {
+			t.Errorf("%v: wrong language %q", test.url, lang)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/de/produkte/42", nil)
+	router.ServeHTTP(w, r)
+	want := map[string]string{
+		"de":     "/de/produkte/42",
+		"en":     "/en/products/42",
+		"es-ES":  "/es-ES/products/42",
+		"pt":     "/pt/produtos/42",
+		XDefault: "/en/products/42",
+	}
+	if len(alts) != len(want)+1 {
+		t.Fatalf("wrong alternates %v", alts)
+	}
+	for _, alt := range alts {
+		if u, found := want[alt.Lang]; found && alt.URL != u {
+			t.Errorf("wrong alternate %v", alt)
+		}
+	}
+
+	ps := Params{{"id", "7"}}
+	for lang, path := range map[string]string{
+		"":      "/products/7",
+		"en":    "/en/products/7",
+		"pt":    "/pt/produtos/7",
+		"pt-BR": "/pt-BR/produtos/7",
+		"de":    "/de/produkte/7",
+	} {
+		if p := rt.LocalPath(lang, ps); p != path {
+			t.Errorf("wrong path of %q: %v", lang, p)
+		}
+	}
+	if p := rt.LocalPath("pt", Params{{"id", "a/b?x=1"}}); p != "/pt/produtos/a%2Fb%3Fx=1" {
+		t.Errorf("wrong escaped path %v", p)
+	}
+}
+
+func TestLocalizePanics(t *testing.T) {
+	router := langRouter()
+	rt := router.GET("/products/:id", true, func(w http.ResponseWriter, r *http.Request) {})
+	plain := router.GET("/plain", false, func(w http.ResponseWriter, r *http.Request) {})
+	rt.Localize("pt", "/produtos/:id")
+
+	tests := []func(){
+		func() { plain.Localize("pt", "/simples") },
+		func() { rt.Localize("pt", "/artigos/:id") },
+		func() { rt.Localize("de", "/produkte/:name") },
+		func() { rt.Localize("", "/items/:id") },
+	}
+	for i, test := range tests {
+		recv := catchPanic(test)
+		if recv == nil {
+			t.Errorf("%v: no panic", i)
+		}
+	}
+}
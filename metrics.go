@@ -0,0 +1,272 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"bufio"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histogram buckets.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultSizeBuckets are the upper bounds, in bytes, of the response size
+// histogram buckets.
+var DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7, 1e8}
+
+// unmatched is the route label of the requests that didn't match a route.
+const unmatched = "unmatched"
+
+// otherMethod is the method label of the unmatched requests with a
+// non-standard method.
+const otherMethod = "other"
+
+// Metrics collects the request count, the in-flight requests, the latency
+// and the response size of the requests served by the router. The series
+// are labeled by method, route pattern, status class and language. Metrics
+// is a http.Handler that serves them in the Prometheus text format, mount it
+// in the route of your choice:
+//  router.Metrics = httprouter.NewMetrics()
+//  router.Handler("GET", "/metrics", false, router.Metrics)
+// The zero value is ready to use.
+type Metrics struct {
+	// Upper bounds of the latency histogram buckets in seconds. If it is
+	// nil, DefaultLatencyBuckets are used.
+	LatencyBuckets []float64
+	// Upper bounds of the response size histogram buckets in bytes. If it
+	// is nil, DefaultSizeBuckets are used.
+	SizeBuckets []float64
+
+	mu       sync.Mutex
+	series   map[metricLabels]*metricSeries
+	inflight map[inflightLabels]int64
+}
+
+type metricLabels struct {
+	method, route, status, lang string
+}
+
+type inflightLabels struct {
+	method, route string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+}
+
+func (h *histogram) observe(bounds []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(bounds))
+	}
+	for i, b := range bounds {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+}
+
+type metricSeries struct {
+	count   uint64
+	latency histogram
+	size    histogram
+}
+
+// NewMetrics creates a Metrics with the default buckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		LatencyBuckets: DefaultLatencyBuckets,
+		SizeBuckets:    DefaultSizeBuckets,
+		series:         make(map[metricLabels]*metricSeries),
+		inflight:       make(map[inflightLabels]int64),
+	}
+}
+
+// lazyInit allocates the series of a Metrics that wasn't created by
+// NewMetrics. It must be called with the lock held.
+func (m *Metrics) lazyInit() {
+	if m.LatencyBuckets == nil {
+		m.LatencyBuckets = DefaultLatencyBuckets
+	}
+	if m.SizeBuckets == nil {
+		m.SizeBuckets = DefaultSizeBuckets
+	}
+	if m.series == nil {
+		m.series = make(map[metricLabels]*metricSeries)
+	}
+	if m.inflight == nil {
+		m.inflight = make(map[inflightLabels]int64)
+	}
+}
+
+// methodLabel returns the method label of a request. The methods of the
+// routes are bounded by the registered ones, but the clients may send any
+// method to the unmatched paths, so the non-standard ones are counted
+// together.
+func methodLabel(method string, matched bool) string {
+	if matched {
+		return method
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodConnect,
+		http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
+
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// begin counts a request of the route in flight.
+func (m *Metrics) begin(method, route string) {
+	m.mu.Lock()
+	m.lazyInit()
+	m.inflight[inflightLabels{method, route}]++
+	m.mu.Unlock()
+}
+
+// end records the request described by entry. inflight is true if begin
+// was called for it.
+func (m *Metrics) end(entry *AccessEntry, inflight bool) {
+	route := entry.Pattern
+	if route == "" {
+		route = unmatched
+	}
+	l := metricLabels{
+		method: methodLabel(entry.Method, entry.Pattern != ""),
+		route:  route,
+		status: statusClass(entry.Status),
+		lang:   entry.Lang,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lazyInit()
+	if inflight {
+		m.inflight[inflightLabels{entry.Method, route}]--
+	}
+	s := m.series[l]
+	if s == nil {
+		s = &metricSeries{}
+		m.series[l] = s
+	}
+	s.count++
+	s.latency.observe(m.LatencyBuckets, entry.Latency.Seconds())
+	s.size.observe(m.SizeBuckets, float64(entry.Bytes))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (l metricLabels) String() string {
+	return `method="` + labelEscaper.Replace(l.method) +
+		`",route="` + labelEscaper.Replace(l.route) +
+		`",status="` + l.status +
+		`",lang="` + labelEscaper.Replace(l.lang) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lazyInit()
+
+	labels := make([]metricLabels, 0, len(m.series))
+	for l := range m.series {
+		labels = append(labels, l)
+	}
+	sort.Sort(byLabels(labels))
+
+	rw.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w := bufio.NewWriter(rw)
+	defer w.Flush()
+
+	w.WriteString("# HELP httprouter_requests_total Number of requests served.\n")
+	w.WriteString("# TYPE httprouter_requests_total counter\n")
+	for _, l := range labels {
+		w.WriteString("httprouter_requests_total{" + l.String() + "} " + strconv.FormatUint(m.series[l].count, 10) + "\n")
+	}
+
+	inflight := make([]inflightLabels, 0, len(m.inflight))
+	for l := range m.inflight {
+		inflight = append(inflight, l)
+	}
+	sort.Sort(byInflight(inflight))
+	w.WriteString("# HELP httprouter_requests_in_flight Number of requests being served.\n")
+	w.WriteString("# TYPE httprouter_requests_in_flight gauge\n")
+	for _, l := range inflight {
+		w.WriteString(`httprouter_requests_in_flight{method="` + labelEscaper.Replace(l.method) +
+			`",route="` + labelEscaper.Replace(l.route) + `"} ` +
+			strconv.FormatInt(m.inflight[l], 10) + "\n")
+	}
+
+	writeHistogram(w, "httprouter_request_duration_seconds", "Time spent serving the requests.",
+		labels, m.LatencyBuckets, func(s *metricSeries) *histogram { return &s.latency }, m.series)
+	writeHistogram(w, "httprouter_response_size_bytes", "Size of the response bodies.",
+		labels, m.SizeBuckets, func(s *metricSeries) *histogram { return &s.size }, m.series)
+}
+
+func writeHistogram(w *bufio.Writer, name, help string, labels []metricLabels, bounds []float64, get func(*metricSeries) *histogram, series map[metricLabels]*metricSeries) {
+	w.WriteString("# HELP " + name + " " + help + "\n")
+	w.WriteString("# TYPE " + name + " histogram\n")
+	for _, l := range labels {
+		s := series[l]
+		h := get(s)
+		ls := l.String()
+		for i, b := range bounds {
+			var c uint64
+			if h.counts != nil {
+				c = h.counts[i]
+			}
+			w.WriteString(name + "_bucket{" + ls + `,le="` + formatFloat(b) + `"} ` + strconv.FormatUint(c, 10) + "\n")
+		}
+		count := strconv.FormatUint(s.count, 10)
+		w.WriteString(name + "_bucket{" + ls + `,le="+Inf"} ` + count + "\n")
+		w.WriteString(name + "_sum{" + ls + "} " + formatFloat(h.sum) + "\n")
+		w.WriteString(name + "_count{" + ls + "} " + count + "\n")
+	}
+}
+
+type byLabels []metricLabels
+
+func (ls byLabels) Len() int      { return len(ls) }
+func (ls byLabels) Swap(i, j int) { ls[i], ls[j] = ls[j], ls[i] }
+func (ls byLabels) Less(i, j int) bool {
+	a, b := ls[i], ls[j]
+	switch {
+	case a.route != b.route:
+		return a.route < b.route
+	case a.method != b.method:
+		return a.method < b.method
+	case a.status != b.status:
+		return a.status < b.status
+	}
+	return a.lang < b.lang
+}
+
+type byInflight []inflightLabels
+
+func (ls byInflight) Len() int      { return len(ls) }
+func (ls byInflight) Swap(i, j int) { ls[i], ls[j] = ls[j], ls[i] }
+func (ls byInflight) Less(i, j int) bool {
+	if ls[i].route != ls[j].route {
+		return ls[i].route < ls[j].route
+	}
+	return ls[i].method < ls[j].method
+}
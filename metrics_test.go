@@ -0,0 +1,86 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	router := New()
+	router.AccessLog = nil
+	router.Metrics = NewMetrics()
+	router.Handler("GET", "/metrics", false, router.Metrics)
+	router.GET("/user/:name", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("catotos"))
+	})
+
+	for _, path := range []string{"/user/gopher", "/user/gordon", "/nope"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, r)
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatal("wrong content type", w.Header())
+	}
+	body := w.Body.String()
+	lines := []string{
+		`httprouter_requests_total{method="GET",route="/user/:name",status="2xx",lang=""} 2`,
+		`httprouter_requests_total{method="GET",route="unmatched",status="4xx",lang=""} 1`,
+		`httprouter_requests_in_flight{method="GET",route="/metrics"} 1`,
+		`httprouter_requests_in_flight{method="GET",route="/user/:name"} 0`,
+		`httprouter_request_duration_seconds_count{method="GET",route="/user/:name",status="2xx",lang=""} 2`,
+		`httprouter_response_size_bytes_bucket{method="GET",route="/user/:name",status="2xx",lang="",le="100"} 2`,
+		`httprouter_response_size_bytes_sum{method="GET",route="/user/:name",status="2xx",lang=""} 14`,
+		`# TYPE httprouter_request_duration_seconds histogram`,
+	}
+	for _, line := range lines {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("line not found: %v", line)
+		}
+	}
+	if strings.Contains(body, "gopher") {
+		t.Error("raw path used as label")
+	}
+}
+
+func TestMetricsZeroValue(t *testing.T) {
+	router := New()
+	router.AccessLog = nil
+	router.Metrics = &Metrics{}
+	router.Handler("GET", "/metrics", false, router.Metrics)
+	router.Handle("PURGE", "/cache", false, func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, method := range []string{"GET", "FOO", "BAR", "PURGE"} {
+		for _, path := range []string{"/nope", "/cache"} {
+			r, _ := http.NewRequest(method, path, nil)
+			router.ServeHTTP(httptest.NewRecorder(), r)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, r)
+	body := w.Body.String()
+	for _, line := range []string{
+		`httprouter_requests_total{method="GET",route="unmatched",status="4xx",lang=""} 2`,
+		`httprouter_requests_total{method="other",route="unmatched",status="4xx",lang=""} 5`,
+		`httprouter_requests_total{method="PURGE",route="/cache",status="2xx",lang=""} 1`,
+		`httprouter_request_duration_seconds_bucket{method="PURGE",route="/cache",status="2xx",lang="",le="10"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("line not found: %v", line)
+		}
+	}
+	if strings.Contains(body, "FOO") || strings.Contains(body, "BAR") {
+		t.Error("non-standard method used as label")
+	}
+}
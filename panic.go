@@ -0,0 +1,65 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	log "github.com/fcavani/slog"
+)
+
+// PanicInfo describes a panic recovered from a handle.
+type PanicInfo struct {
+	// Value passed to panic.
+	Value interface{}
+	// Stack trace of the goroutine that panicked.
+	Stack []byte
+	// Path pattern of the route, empty if the panic didn't happen in the
+	// handle of a route.
+	Pattern string
+	// ID of the request, see Router.RequestIDHeader.
+	RequestID string
+}
+
+// newPanicInfo must be called from the deferred function that recovered
+// the panic, so the stack still has the frames of the panic.
+func newPanicInfo(rcv interface{}, rt *Route, req *http.Request) *PanicInfo {
+	p := &PanicInfo{
+		Value:     rcv,
+		Stack:     debug.Stack(),
+		RequestID: RequestID(req),
+	}
+	if rt != nil {
+		p.Pattern = rt.path
+	}
+	return p
+}
+
+// recovered logs the panic and calls the panic handler. Without one, what
+// the handle wrote is discarded and the router replies with a 500 (Internal
+// Server Error), unless the response was already sent to the client.
+func (r *Router) recovered(w http.ResponseWriter, req *http.Request, p *PanicInfo) {
+	log.ErrorLevel().Tag("httprouter", "panic").Printf("Handler panicked. RequestID=%v Method=%v Path=%v Pattern=%v Panic: %v\n%s", p.RequestID, req.Method, req.URL.Path, p.Pattern, p.Value, p.Stack)
+	if r.PanicHandler != nil {
+		r.PanicHandler(w, req, p)
+		return
+	}
+	if discard(w) {
+		r.httpError(w, req, http.StatusInternalServerError, "")
+	}
+}
+
+// discard drops the response written so far to the buffered w. It returns
+// false if the response was already sent to the client.
+func discard(w http.ResponseWriter) bool {
+	if rw, ok := w.(*ResponseWriter); ok {
+		if rw.Committed() {
+			return false
+		}
+		rw.Reset()
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDefaultPanicHandler(t *testing.T) {
+	router := New()
+	router.RequestIDHeader = "X-Request-ID"
+	// Static routes run in their own goroutine, the ones with parameters
+	// run in the ServeHTTP goroutine.
+	for _, path := range []string{"/static", "/param/:name"} {
+		router.GET(path, false, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Partial", "1")
+			w.Write([]byte("partial"))
+			panic("oops!")
+		})
+	}
+
+	for _, path := range []string{"/static", "/param/gopher"} {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%v: wrong status %v", path, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "partial") || w.Header().Get("X-Partial") != "" {
+			t.Errorf("%v: partial response was sent: %q", path, w.Body.String())
+		}
+		if w.Header().Get("X-Request-ID") == "" {
+			t.Errorf("%v: request id is missing", path)
+		}
+	}
+
+	router.ProblemDetails = true
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/static", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("wrong problem response %v %v", w.Code, w.Header())
+	}
+}
+
+func TestPanicHandlerGoroutine(t *testing.T) {
+	router := New()
+	router.RequestIDHeader = "X-Request-ID"
+	var info *PanicInfo
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, p *PanicInfo) {
+		info = p
+		w.WriteHeader(http.StatusTeapot)
+	}
+	router.GET("/static", false, func(w http.ResponseWriter, r *http.Request) {
+		panic("oops!")
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/static", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("wrong status %v", w.Code)
+	}
+	if info == nil {
+		t.Fatal("panic handler wasn't called")
+	}
+	if info.Value != "oops!" || info.Pattern != "/static" {
+		t.Fatalf("wrong panic info %#v", info)
+	}
+	if info.RequestID == "" || info.RequestID != w.Header().Get("X-Request-ID") {
+		t.Fatalf("wrong request id %q", info.RequestID)
+	}
+	if !strings.Contains(string(info.Stack), "panic_test.go") {
+		t.Fatalf("stack doesn't have the panic:\n%s", info.Stack)
+	}
+}
+
+func TestPanicAfterCommit(t *testing.T) {
+	router := New()
+	router.MaxBufferSize = 4
+	router.BufferOverflow = Stream
+	router.GET("/p/:name", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("streamed"))
+		panic("oops!")
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/p/x", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != "streamed" {
+		t.Fatalf("committed response changed: %v %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Problem is the RFC 7807 problem details of an error response.
+type Problem struct {
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// problemTypes are the media types of the error responses in the order of
+// preference.
+var problemTypes = []string{
+	"application/problem+json",
+	"application/json",
+	"text/html",
+	"text/plain",
+}
+
+// negotiateProblem returns the media type of the error response accepted by
+// the client, application/problem+json if the client accepts none.
+func negotiateProblem(req *http.Request) string {
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return problemTypes[0]
+	}
+	tps, err := Parse(strings.ToLower(accept))
+	if err != nil {
+		return problemTypes[0]
+	}
+	best, bestQ := problemTypes[0], int32(0)
+	for _, t := range problemTypes {
+		if q := tps.rankType(t); q > bestQ {
+			best, bestQ = t, q
+		}
+	}
+	return best
+}
+
+// WriteProblem writes the error response described by p as problem+json,
+// HTML or plain text, as negotiated with the Accept header of the request.
+func WriteProblem(w http.ResponseWriter, req *http.Request, p *Problem) {
+	header := w.Header()
+	header.Del("Content-Length")
+	header.Set("X-Content-Type-Options", "nosniff")
+	addVary(header, "Accept")
+
+	var body []byte
+	switch t := negotiateProblem(req); t {
+	case "text/html":
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		s := "<!DOCTYPE html>\n<html><head><title>" + strconv.Itoa(p.Status) + " " + html.EscapeString(p.Title) +
+			"</title></head><body><h1>" + html.EscapeString(p.Title) + "</h1>"
+		if p.Detail != "" {
+			s += "<p>" + html.EscapeString(p.Detail) + "</p>"
+		}
+		if p.RequestID != "" {
+			s += "<p>Request ID: " + html.EscapeString(p.RequestID) + "</p>"
+		}
+		body = []byte(s + "</body></html>\n")
+	case "text/plain":
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		s := p.Title + "\n"
+		if p.Detail != "" {
+			s += p.Detail + "\n"
+		}
+		if p.RequestID != "" {
+			s += "Request ID: " + p.RequestID + "\n"
+		}
+		body = []byte(s)
+	default:
+		header.Set("Content-Type", t)
+		b, err := json.Marshal(p)
+		if err != nil {
+			b = []byte("{}")
+		}
+		body = append(b, '\n')
+	}
+	w.WriteHeader(p.Status)
+	w.Write(body)
+}
+
+// httpError writes the error responses generated by the router. detail is
+// the message of the plain text response, the status text if it is empty.
+func (r *Router) httpError(w http.ResponseWriter, req *http.Request, code int, detail string) {
+	if !r.ProblemDetails {
+		if detail == "" {
+			detail = http.StatusText(code)
+		}
+		http.Error(w, detail, code)
+		return
+	}
+	WriteProblem(w, req, &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(code),
+		Status:    code,
+		Detail:    detail,
+		Instance:  req.URL.Path,
+		RequestID: RequestID(req),
+	})
+}
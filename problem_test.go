@@ -0,0 +1,93 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateProblem(t *testing.T) {
+	tests := []struct {
+		accept, want string
+	}{
+		{"", "application/problem+json"},
+		{"*/*", "application/problem+json"},
+		{"application/json", "application/json"},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "text/html"},
+		{"text/*", "text/html"},
+		{"text/plain", "text/plain"},
+		{"image/png", "application/problem+json"},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("GET", "/", nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		if got := negotiateProblem(r); got != test.want {
+			t.Errorf("negotiateProblem(%q) = %v, want %v", test.accept, got, test.want)
+		}
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	router := New()
+	router.ProblemDetails = true
+	router.RequestIDHeader = "X-Request-ID"
+	router.GET("/user/:name", false, func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleErr("GET", "/fail", false, func(w http.ResponseWriter, r *http.Request) error {
+		return NewStatusError(http.StatusConflict, errors.New("secret"))
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/user/gopher", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, OPTIONS" {
+		t.Fatalf("wrong response %v %v", w.Code, w.Header())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("wrong content type %q", ct)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusMethodNotAllowed || p.Title != "Method Not Allowed" ||
+		p.Instance != "/user/gopher" || p.RequestID != w.Header().Get("X-Request-ID") || p.RequestID == "" {
+		t.Fatalf("wrong problem %#v", p)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/fail", nil)
+	r.Header.Set("Accept", "text/html")
+	router.ServeHTTP(w, r)
+	body := w.Body.String()
+	if w.Code != http.StatusConflict || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") ||
+		!strings.Contains(body, "<h1>Conflict</h1>") || strings.Contains(body, "secret") {
+		t.Fatalf("wrong html response %v %q", w.Code, body)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/nope", nil)
+	r.Header.Set("Accept", "text/plain")
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound || !strings.HasPrefix(w.Body.String(), "Not Found\nRequest ID: ") {
+		t.Fatalf("wrong plain response %v %q", w.Code, w.Body.String())
+	}
+}
+
+func TestPlainErrors(t *testing.T) {
+	router := New()
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/nope", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound || w.Body.String() != "404 page not found\n" {
+		t.Fatalf("wrong response %v %q", w.Code, w.Body.String())
+	}
+}
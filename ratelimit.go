@@ -0,0 +1,253 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"container/list"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KeyFunc returns the key that identifies the client of the request for the
+// rate limiter.
+type KeyFunc func(req *http.Request) string
+
+// ClientIPKey identifies the client by its IP address.
+func ClientIPKey(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// UserKey identifies the client by the user of the basic authentication.
+func UserKey(req *http.Request) string {
+	user, _, _ := req.BasicAuth()
+	return user
+}
+
+// HeaderKey identifies the client by the value of the request header name,
+// for example an API key.
+func HeaderKey(name string) KeyFunc {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
+
+// ParamKey identifies the client by the value of the path parameter name.
+func ParamKey(name string) KeyFunc {
+	return func(req *http.Request) string {
+		ps, _ := req.Context().Value("Params").(Params)
+		return ps.ByName(name)
+	}
+}
+
+// LimitResult is the state of a bucket after a token was taken.
+type LimitResult struct {
+	// Allowed is true if there was a token in the bucket.
+	Allowed bool
+	// Tokens left in the bucket.
+	Remaining int
+	// Time until the next token is available.
+	RetryAfter time.Duration
+	// Time until the bucket is full.
+	Reset time.Duration
+}
+
+// LimitStore keeps the token buckets of a rate limiter.
+type LimitStore interface {
+	// Take takes one token from the bucket of key. The bucket holds at
+	// most burst tokens and is refilled with rate tokens per second.
+	Take(key string, rate float64, burst int, now time.Time) LimitResult
+}
+
+// DefaultMaxKeys is the number of buckets kept by the memory store created
+// by NewRateLimiter.
+const DefaultMaxKeys = 10000
+
+// MemoryStore is a LimitStore that keeps the buckets in memory. The full
+// buckets, that are the same as new ones, are dropped, and the least
+// recently used ones are evicted when there are more than MaxKeys buckets.
+// The zero value is ready to use.
+type MemoryStore struct {
+	// MaxKeys is the maximum number of buckets, zero means no limit.
+	MaxKeys int
+
+	mu      sync.Mutex
+	lru     *list.List
+	buckets map[string]*list.Element
+}
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryStore creates a store that keeps at most maxKeys buckets.
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	return &MemoryStore{
+		MaxKeys: maxKeys,
+		lru:     list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+}
+
+// lazyInit allocates the buckets of a store that wasn't created by
+// NewMemoryStore. It must be called with the lock held.
+func (s *MemoryStore) lazyInit() {
+	if s.lru == nil {
+		s.lru = list.New()
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[string]*list.Element)
+	}
+}
+
+// Len returns the number of buckets in the store.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lazyInit()
+	return s.lru.Len()
+}
+
+// refill adds the tokens accumulated since the last time the bucket was
+// used.
+func (b *bucket) refill(rate float64, burst int, now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+		b.last = now
+	}
+}
+
+// Take implements LimitStore.
+func (s *MemoryStore) Take(key string, rate float64, burst int, now time.Time) LimitResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lazyInit()
+
+	e, found := s.buckets[key]
+	if found {
+		s.lru.MoveToFront(e)
+	} else {
+		e = s.lru.PushFront(&bucket{key: key, tokens: float64(burst), last: now})
+		s.buckets[key] = e
+	}
+	b := e.Value.(*bucket)
+	b.refill(rate, burst, now)
+	s.evict(e, rate, burst, now)
+
+	res := LimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else if rate > 0 {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	if rate > 0 {
+		res.Reset = seconds((float64(burst) - b.tokens) / rate)
+	}
+	return res
+}
+
+// evict drops the full buckets and the least recently used ones beyond
+// MaxKeys, except keep.
+func (s *MemoryStore) evict(keep *list.Element, rate float64, burst int, now time.Time) {
+	for e := s.lru.Back(); e != nil && e != keep; e = s.lru.Back() {
+		b := e.Value.(*bucket)
+		b.refill(rate, burst, now)
+		if b.tokens < float64(burst) && (s.MaxKeys <= 0 || s.lru.Len() <= s.MaxKeys) {
+			return
+		}
+		s.lru.Remove(e)
+		delete(s.buckets, b.key)
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// RateLimiter limits the requests of each client with a token bucket. Attach
+// it to a route or to a group:
+//  search := httprouter.NewRateLimiter(1, 10, httprouter.ClientIPKey)
+//  router.GET("/search/:query", false, handle).RateLimit = search
+//  account := router.Group("/account")
+//  account.RateLimit = httprouter.NewRateLimiter(10, 100, httprouter.UserKey)
+// The requests beyond the limit receive a 429 (Too Many Requests) response
+// with the Retry-After header. All responses have the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers.
+type RateLimiter struct {
+	// Rate is the number of requests per second allowed in the long run.
+	Rate float64
+	// Burst is the number of requests allowed at once.
+	Burst int
+	// Key identifies the client. If it returns an empty string the client
+	// IP address is used.
+	Key KeyFunc
+	// Store keeps the buckets of the clients. If it is nil, a MemoryStore
+	// with DefaultMaxKeys is used.
+	Store LimitStore
+
+	once sync.Once
+	// store is the default store.
+	store LimitStore
+}
+
+// NewRateLimiter creates a rate limiter that allows rate requests per second
+// and bursts of burst requests for each key, with a memory store.
+func NewRateLimiter(rate float64, burst int, key KeyFunc) *RateLimiter {
+	return &RateLimiter{
+		Rate:  rate,
+		Burst: burst,
+		Key:   key,
+		Store: NewMemoryStore(DefaultMaxKeys),
+	}
+}
+
+// allow takes a token for the client of req and sets the RateLimit headers.
+// It returns false if there is no token.
+func (l *RateLimiter) allow(w http.ResponseWriter, req *http.Request) bool {
+	key := ""
+	if l.Key != nil {
+		key = l.Key(req)
+	}
+	if key == "" {
+		key = ClientIPKey(req)
+	}
+	res := l.limitStore().Take(key, l.Rate, l.Burst, time.Now())
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(l.Burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+	if res.Allowed {
+		return true
+	}
+	header.Set("Retry-After", ceilSeconds(res.RetryAfter))
+	return false
+}
+
+// limitStore returns the store of the limiter.
+func (l *RateLimiter) limitStore() LimitStore {
+	if l.Store != nil {
+		return l.Store
+	}
+	l.once.Do(func() {
+		l.store = NewMemoryStore(DefaultMaxKeys)
+	})
+	return l.store
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
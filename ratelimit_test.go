@@ -0,0 +1,133 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(2)
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if res := s.Take("a", 1, 2, now); !res.Allowed || res.Remaining != 1-i {
+			t.Fatalf("take %v: %#v", i, res)
+		}
+	}
+	res := s.Take("a", 1, 2, now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Fatalf("bucket isn't empty: %#v", res)
+	}
+	if res := s.Take("a", 1, 2, now.Add(time.Second)); !res.Allowed {
+		t.Fatalf("bucket wasn't refilled: %#v", res)
+	}
+
+	s.Take("b", 1, 2, now)
+	s.Take("c", 1, 2, now)
+	if s.Len() != 2 {
+		t.Fatalf("wrong number of buckets %v", s.Len())
+	}
+	if _, found := s.buckets["a"]; found {
+		t.Fatal("least recently used bucket wasn't evicted")
+	}
+
+	// Full buckets are dropped.
+	s.Take("d", 1, 2, now.Add(time.Minute))
+	if s.Len() != 1 {
+		t.Fatalf("full buckets weren't dropped: %v", s.Len())
+	}
+}
+
+func TestKeyFuncs(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Api-Key", "key")
+	r.SetBasicAuth("gopher", "secret")
+	if k := ClientIPKey(r); k != "10.0.0.1" {
+		t.Errorf("wrong client ip %q", k)
+	}
+	if k := UserKey(r); k != "gopher" {
+		t.Errorf("wrong user %q", k)
+	}
+	if k := HeaderKey("X-Api-Key")(r); k != "key" {
+		t.Errorf("wrong header %q", k)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	router := New()
+	router.GET("/search/:query", false, func(w http.ResponseWriter, r *http.Request) {}).RateLimit =
+		NewRateLimiter(1, 2, ParamKey("query"))
+	router.GET("/free", false, func(w http.ResponseWriter, r *http.Request) {})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", path, nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		w := serve("/search/go")
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %v was limited", i)
+		}
+		if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") == "" || w.Header().Get("RateLimit-Reset") == "" {
+			t.Fatalf("missing RateLimit headers: %v", w.Header())
+		}
+	}
+	w := serve("/search/go")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("wrong status %v", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("wrong headers %v", w.Header())
+	}
+	if w := serve("/search/other"); w.Code != http.StatusOK {
+		t.Fatalf("other key was limited: %v", w.Code)
+	}
+	for i := 0; i < 3; i++ {
+		if w := serve("/free"); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("route without limiter was limited: %v", w.Code)
+		}
+	}
+}
+
+func TestRateLimitWithoutStore(t *testing.T) {
+	router := New()
+	router.GET("/search", false, func(w http.ResponseWriter, r *http.Request) {}).RateLimit = &RateLimiter{Rate: 1, Burst: 2}
+	codes := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i, code := range codes {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/search", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(w, r)
+		if w.Code != code {
+			t.Fatalf("request %v: wrong status %v", i, w.Code)
+		}
+	}
+}
+
+func TestMemoryStoreZeroValue(t *testing.T) {
+	router := New()
+	store := &MemoryStore{MaxKeys: 1000}
+	router.GET("/search", false, func(w http.ResponseWriter, r *http.Request) {}).RateLimit = &RateLimiter{Rate: 1, Burst: 1, Store: store}
+	for i, code := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/search", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		router.ServeHTTP(w, r)
+		if w.Code != code {
+			t.Fatalf("request %v: wrong status %v", i, w.Code)
+		}
+	}
+	if store.Len() != 1 || (&MemoryStore{}).Len() != 0 {
+		t.Fatalf("wrong number of buckets %v", store.Len())
+	}
+}
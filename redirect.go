@@ -0,0 +1,146 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import "net/http"
+
+// RedirectKind is the reason of a redirect made by the router.
+type RedirectKind uint8
+
+const (
+	// TrailingSlashRedirect adds or removes the trailing slash, see
+	// Router.RedirectTrailingSlash.
+	TrailingSlashRedirect RedirectKind = iota
+	// FixedPathRedirect redirects to the cleaned and case corrected path,
+	// see Router.RedirectFixedPath.
+	FixedPathRedirect
+	// LangRedirect adds the language prefix to the path.
+	LangRedirect
+	// RuleRedirect is made by a redirect rule, see Router.Rules.
+	RuleRedirect
+)
+
+func (k RedirectKind) String() string {
+	switch k {
+	case TrailingSlashRedirect:
+		return "trailing-slash"
+	case FixedPathRedirect:
+		return "fixed-path"
+	case LangRedirect:
+		return "lang"
+	case RuleRedirect:
+		return "rule"
+	}
+	return "unknown"
+}
+
+// RedirectEvent describes a redirect made by the router.
+type RedirectEvent struct {
+	Kind RedirectKind
+	Code int
+	// URL requested by the client.
+	From string
+	// Location sent to the client.
+	To string
+}
+
+// RedirectPolicy configures the redirects made by the router.
+type RedirectPolicy struct {
+	// Codes maps a kind to the status code of its redirects. The kinds
+	// without a code use Permanent. The redirects of the rules always use
+	// the code of the rule.
+	Codes map[RedirectKind]int
+	// If true the redirects are permanent, 301 (Moved Permanently) for GET
+	// and HEAD requests and 308 (Permanent Redirect) for the others.
+	// Otherwise they are temporary, 302 (Found) and 307 (Temporary
+	// Redirect).
+	Permanent bool
+	// If Host is not empty the Location header has the absolute URL with
+	// this scheme and host. If Scheme is empty the scheme of the request is
+	// used.
+	Scheme string
+	Host   string
+	// OnRedirect is called after each redirect, for example to count the
+	// requests to non-canonical URLs.
+	OnRedirect func(req *http.Request, ev *RedirectEvent)
+}
+
+// code returns the status code of the redirect.
+func (p *RedirectPolicy) code(req *http.Request, kind RedirectKind) int {
+	if code, found := p.Codes[kind]; found {
+		return code
+	}
+	safe := req.Method == http.MethodGet || req.Method == http.MethodHead
+	switch {
+	case p.Permanent && safe:
+		return http.StatusMovedPermanently
+	case p.Permanent:
+		return http.StatusPermanentRedirect
+	case safe:
+		return http.StatusFound
+	}
+	return http.StatusTemporaryRedirect
+}
+
+// location returns the URL of the redirect to the URL of req.
+func (p *RedirectPolicy) location(req *http.Request) string {
+	if p.Host == "" {
+		return req.URL.String()
+	}
+	u := *req.URL
+	u.Host = p.Host
+	u.Scheme = p.Scheme
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if req.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return u.String()
+}
+
+// defaultRedirectCode is the status code of the redirects when there is no
+// RedirectPolicy: permanent, but the language redirects of requests other
+// than GET are temporary.
+func defaultRedirectCode(req *http.Request, kind RedirectKind) int {
+	if req.Method == http.MethodGet {
+		return http.StatusMovedPermanently
+	}
+	if kind == LangRedirect {
+		return http.StatusTemporaryRedirect
+	}
+	return http.StatusPermanentRedirect
+}
+
+// redirect replies to the request with a redirect to path.
+func (r *Router) redirect(w http.ResponseWriter, req *http.Request, kind RedirectKind, path string) {
+	from := req.URL.String()
+	req.URL.Path = path
+	p := r.RedirectPolicy
+	code := defaultRedirectCode(req, kind)
+	to := req.URL.String()
+	if p != nil {
+		code = p.code(req, kind)
+		to = p.location(req)
+	}
+	r.sendRedirect(w, req, &RedirectEvent{
+		Kind: kind,
+		Code: code,
+		From: from,
+		To:   to,
+	})
+}
+
+// sendRedirect replies to the request with the redirect described by ev.
+func (r *Router) sendRedirect(w http.ResponseWriter, req *http.Request, ev *RedirectEvent) {
+	http.Redirect(w, req, ev.To, ev.Code)
+	ev.To = w.Header().Get("Location")
+	if span := spanFromRequest(req); span != nil {
+		span.Redirect(ev.Code, ev.To)
+	}
+	if p := r.RedirectPolicy; p != nil && p.OnRedirect != nil {
+		p.OnRedirect(req, ev)
+	}
+}
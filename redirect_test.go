@@ -0,0 +1,69 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	router := New()
+	var events []*RedirectEvent
+	router.RedirectPolicy = &RedirectPolicy{
+		Codes: map[RedirectKind]int{
+			FixedPathRedirect: http.StatusMovedPermanently,
+		},
+		Scheme: "https",
+		Host:   "www.example.com",
+		OnRedirect: func(req *http.Request, ev *RedirectEvent) {
+			events = append(events, ev)
+		},
+	}
+	handle := func(w http.ResponseWriter, r *http.Request) {}
+	router.GET("/path/", false, handle)
+	router.POST("/path/", false, handle)
+
+	tests := []struct {
+		method, url string
+		code        int
+		location    string
+		kind        RedirectKind
+	}{
+		{"GET", "/path?x=1", http.StatusFound, "https://www.example.com/path/?x=1", TrailingSlashRedirect},
+		{"POST", "/path", http.StatusTemporaryRedirect, "https://www.example.com/path/", TrailingSlashRedirect},
+		{"GET", "/PATH/", http.StatusMovedPermanently, "https://www.example.com/path/", FixedPathRedirect},
+	}
+	for i, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(test.method, test.url, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != test.code || w.Header().Get("Location") != test.location {
+			t.Errorf("%v %v: wrong redirect %v %v", test.method, test.url, w.Code, w.Header().Get("Location"))
+		}
+		if len(events) != i+1 {
+			t.Fatalf("%v %v: missing event", test.method, test.url)
+		}
+		ev := events[i]
+		if ev.Kind != test.kind || ev.Code != test.code || ev.From != test.url || ev.To != test.location {
+			t.Errorf("%v %v: wrong event %#v", test.method, test.url, ev)
+		}
+	}
+
+	router.RedirectPolicy = &RedirectPolicy{Permanent: true}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("POST", "/path", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/path/" {
+		t.Fatalf("wrong redirect %v %v", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestRedirectKindString(t *testing.T) {
+	if LangRedirect.String() != "lang" || RedirectKind(99).String() != "unknown" {
+		t.Fatal("wrong redirect kind names")
+	}
+}
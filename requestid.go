@@ -0,0 +1,66 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+// RequestID returns the request ID the router assigned to the request, or
+// an empty string if the router doesn't assign request IDs.
+func RequestID(req *http.Request) string {
+	id, _ := req.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random request ID with 32 hexadecimal digits.
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ValidRequestID returns true if id can be used as a request ID: it must
+// have between 1 and 128 characters, all letters, digits or one of -_.:+/=
+func ValidRequestID(id string) bool {
+	if len(id) < 1 || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// requestID assigns the request ID to the request and echoes it in the
+// response headers.
+func (r *Router) requestID(rw http.ResponseWriter, req *http.Request) *http.Request {
+	id := ""
+	if r.TrustRequestID {
+		if in := req.Header.Get(r.RequestIDHeader); ValidRequestID(in) {
+			id = in
+		}
+	}
+	if id == "" {
+		if r.GenerateRequestID != nil {
+			id = r.GenerateRequestID()
+		} else {
+			id = NewRequestID()
+		}
+	}
+	rw.Header().Set(r.RequestIDHeader, id)
+	return req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id))
+}
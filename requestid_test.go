@@ -0,0 +1,83 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidRequestID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"abc-123", true},
+		{"a.b_c:d+e/f=", true},
+		{"", false},
+		{"with space", false},
+		{"new\nline", false},
+		{strings.Repeat("a", 128), true},
+		{strings.Repeat("a", 129), false},
+	}
+	for _, test := range tests {
+		if got := ValidRequestID(test.id); got != test.valid {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", test.id, got, test.valid)
+		}
+	}
+	if id := NewRequestID(); len(id) != 32 || !ValidRequestID(id) {
+		t.Errorf("invalid generated id %q", id)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	router := New()
+	logger := &recordLogger{}
+	router.AccessLog = logger
+	router.RequestIDHeader = "X-Request-ID"
+	var got string
+	router.GET("/id", false, func(w http.ResponseWriter, r *http.Request) {
+		got = RequestID(r)
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/id", nil)
+	r.Header.Set("X-Request-ID", "from-client")
+	router.ServeHTTP(w, r)
+	if got == "" || got == "from-client" {
+		t.Fatalf("untrusted request id was used: %q", got)
+	}
+	if h := w.Header().Get("X-Request-ID"); h != got {
+		t.Fatalf("response header %q, want %q", h, got)
+	}
+	if logger.entries[0].RequestID != got {
+		t.Fatalf("access log request id %q, want %q", logger.entries[0].RequestID, got)
+	}
+
+	router.TrustRequestID = true
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if got != "from-client" || w.Header().Get("X-Request-ID") != "from-client" {
+		t.Fatalf("trusted request id wasn't used: %q", got)
+	}
+
+	router.GenerateRequestID = func() string { return "generated" }
+	r.Header.Set("X-Request-ID", "bad id")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if got != "generated" || w.Header().Get("X-Request-ID") != "generated" {
+		t.Fatalf("invalid request id wasn't replaced: %q", got)
+	}
+
+	// The id survives a response rebuilt by the router.
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/nope", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound || w.Header().Get("X-Request-ID") != "generated" {
+		t.Fatalf("missing request id in %v response", w.Code)
+	}
+}
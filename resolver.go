@@ -0,0 +1,206 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// LangResolver finds the languages requested by the client, see
+// Router.LangResolvers.
+type LangResolver interface {
+	// ResolveLang returns the language tags requested, in the order of
+	// preference, or nil if the request doesn't say.
+	ResolveLang(req *http.Request) []string
+}
+
+// LangResolverFunc is an adapter to use a function as a LangResolver.
+type LangResolverFunc func(req *http.Request) []string
+
+// ResolveLang calls f(req).
+func (f LangResolverFunc) ResolveLang(req *http.Request) []string {
+	return f(req)
+}
+
+// LangVary is implemented by the resolvers that read request headers. The
+// headers are added to the Vary header of the responses whose language was
+// resolved.
+type LangVary interface {
+	Vary() []string
+}
+
+type varyResolver struct {
+	LangResolverFunc
+	vary []string
+}
+
+func (v varyResolver) Vary() []string {
+	return v.vary
+}
+
+// LangStrategy is what the router does when the path of an i18n route has
+// no language prefix.
+type LangStrategy uint8
+
+const (
+	// RedirectLang redirects the client to the path with the language
+	// prefix.
+	RedirectLang LangStrategy = iota
+	// ServeLang serves the request in place, in the resolved language.
+	ServeLang
+)
+
+// LangUnavailable is what the router does when the language in the path
+// prefix isn't one of the languages of the route, see Route.Langs.
+type LangUnavailable uint8
+
+const (
+	// RedirectAvailableLang redirects the client to the path with the best
+	// language of the route.
+	RedirectAvailableLang LangUnavailable = iota
+	// ServeAvailableLang serves the request in place, in the best language
+	// of the route.
+	ServeAvailableLang
+	// NotFoundLang replies with 404 (Not Found).
+	NotFoundLang
+)
+
+// AcceptLanguage resolves the languages of the Accept-Language header,
+// ordered by their quality values.
+func AcceptLanguage() LangResolver {
+	return varyResolver{acceptLangs, []string{"Accept-Language"}}
+}
+
+func acceptLangs(req *http.Request) []string {
+	params := req.Header.Get("Accept-Language")
+	if params == "" {
+		return nil
+	}
+	parsed, err := ParseLang(strings.ToLower(params))
+	if err != nil {
+		return nil
+	}
+	tags := make([]string, 0, len(parsed))
+	for _, tp := range parsed {
+		if tp.Q > 0 {
+			tags = append(tags, tp.Type)
+		}
+	}
+	return tags
+}
+
+func single(tag string) []string {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil
+	}
+	return []string{tag}
+}
+
+// CookieLang resolves the language stored in the cookie name.
+func CookieLang(name string) LangResolver {
+	return varyResolver{func(req *http.Request) []string {
+		c, err := req.Cookie(name)
+		if err != nil {
+			return nil
+		}
+		return single(c.Value)
+	}, []string{"Cookie"}}
+}
+
+// QueryLang resolves the language in the query parameter name, like
+// ?lang=pt.
+func QueryLang(name string) LangResolver {
+	return LangResolverFunc(func(req *http.Request) []string {
+		return single(req.URL.Query().Get(name))
+	})
+}
+
+// HeaderLang resolves the language in the request header name.
+func HeaderLang(name string) LangResolver {
+	return varyResolver{func(req *http.Request) []string {
+		return single(req.Header.Get(name))
+	}, []string{http.CanonicalHeaderKey(name)}}
+}
+
+func hostLabels(req *http.Request) []string {
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+	if host == "" || net.ParseIP(host) != nil {
+		return nil
+	}
+	return strings.Split(host, ".")
+}
+
+// SubdomainLang resolves the language in the first label of the host name,
+// like pt.example.com.
+func SubdomainLang() LangResolver {
+	return LangResolverFunc(func(req *http.Request) []string {
+		labels := hostLabels(req)
+		if len(labels) < 3 {
+			return nil
+		}
+		return single(labels[0])
+	})
+}
+
+// TLDLang resolves the language of the top-level domain of the host name.
+// tlds maps the top-level domains to the languages, like "br" to "pt-BR". If
+// tlds is nil the top-level domain is the language.
+func TLDLang(tlds map[string]string) LangResolver {
+	return LangResolverFunc(func(req *http.Request) []string {
+		labels := hostLabels(req)
+		if len(labels) < 2 {
+			return nil
+		}
+		tld := strings.ToLower(labels[len(labels)-1])
+		if tlds == nil {
+			return single(tld)
+		}
+		return single(tlds[tld])
+	})
+}
+
+// langResolvers returns the resolvers in the order they are tried.
+func (r *Router) langResolvers() []LangResolver {
+	resolvers := r.LangResolvers
+	if resolvers == nil {
+		resolvers = []LangResolver{AcceptLanguage()}
+	}
+	if r.LangCookie != nil {
+		resolvers = append([]LangResolver{CookieLang(r.LangCookie.name())}, resolvers...)
+	}
+	return resolvers
+}
+
+// varyLang adds to the Vary header the request headers read by the
+// resolvers.
+func (r *Router) varyLang(header http.Header) {
+	for _, resolver := range r.langResolvers() {
+		if v, ok := resolver.(LangVary); ok {
+			for _, field := range v.Vary() {
+				addVary(header, field)
+			}
+		}
+	}
+}
+
+// resolveLang returns the first supported language found in the language
+// cookie or by the resolvers, or an empty string.
+func (r *Router) resolveLang(req *http.Request) string {
+	for _, resolver := range r.langResolvers() {
+		for _, tag := range resolver.ResolveLang(req) {
+			if lang := r.matchLang(tag); lang != "" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestLangResolvers(t *testing.T) {
+	r, _ := http.NewRequest("GET", "http://pt.example.com.br:8080/path?lang=es", nil)
+	r.Header.Set("Accept-Language", "en-US,en;q=0.5,de;q=0")
+	r.Header.Set("X-Lang", "fr")
+	r.AddCookie(&http.Cookie{Name: "lang", Value: "it"})
+
+	tests := []struct {
+		resolver LangResolver
+		tags     []string
+	}{
+		{AcceptLanguage(), []string{"en-us", "en"}},
+		{CookieLang("lang"), []string{"it"}},
+		{CookieLang("none"), nil},
+		{QueryLang("lang"), []string{"es"}},
+		{HeaderLang("X-Lang"), []string{"fr"}},
+		{SubdomainLang(), []string{"pt"}},
+		{TLDLang(nil), []string{"br"}},
+		{TLDLang(map[string]string{"br": "pt-BR"}), []string{"pt-BR"}},
+		{TLDLang(map[string]string{"de": "de"}), nil},
+	}
+	for i, test := range tests {
+		if tags := test.resolver.ResolveLang(r); !reflect.DeepEqual(tags, test.tags) {
+			t.Errorf("%v: wrong tags %v, want %v", i, tags, test.tags)
+		}
+	}
+
+	r, _ = http.NewRequest("GET", "http://127.0.0.1/path", nil)
+	if tags := SubdomainLang().ResolveLang(r); tags != nil {
+		t.Errorf("ip address resolved to %v", tags)
+	}
+}
+
+func TestLangStrategy(t *testing.T) {
+	router := langRouter()
+	router.LangResolvers = []LangResolver{QueryLang("lang"), CookieLang("lang"), AcceptLanguage()}
+	router.LangStrategy = ServeLang
+	var served string
+	router.GET("/lang", true, func(w http.ResponseWriter, r *http.Request) {
+		served = ContentLang(r)
+	})
+
+	tests := []struct {
+		url, cookie, accept, lang string
+	}{
+		{"/lang?lang=pt", "es-MX", "en", "pt"},
+		{"/lang", "es-MX", "pt", "es-MX"},
+		{"/lang", "", "pt", "pt"},
+		{"/lang?lang=xx", "", "", "en"},
+		{"/es-ES/lang?lang=pt", "", "", "es-ES"},
+	}
+	for _, test := range tests {
+		served = ""
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		if test.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: test.cookie})
+		}
+		if test.accept != "" {
+			r.Header.Set("Accept-Language", test.accept)
+		}
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusOK || served != test.lang {
+			t.Errorf("%v: wrong response %v in %q, want %q", test.url, w.Code, served, test.lang)
+		}
+	}
+
+	router.LangStrategy = RedirectLang
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/lang?lang=pt", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/pt/lang?lang=pt" {
+		t.Fatalf("wrong redirect %v %v", w.Code, w.Header().Get("Location"))
+	}
+}
@@ -6,15 +6,59 @@ package httprouter
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"sync"
+
 	"github.com/fcavani/e"
 )
 
+// Overflow selects what the ResponseWriter does with the body when it grows
+// beyond the memory limit.
+type Overflow uint8
+
+const (
+	// SpillToDisk moves the body to a temporary file and keeps buffering
+	// there.
+	SpillToDisk Overflow = iota
+	// Stream commits the headers and writes the body directly to the client.
+	// If there is no client to write to it falls back to SpillToDisk.
+	Stream
+)
+
+// BufferStats reports how the body of one response was buffered.
+type BufferStats struct {
+	// Memory is the peak number of bytes held in memory.
+	Memory int64
+	// Disk is the number of bytes written to the temporary file.
+	Disk int64
+	// Streamed is the number of bytes written directly to the client.
+	Streamed int64
+}
+
 // ResponseWriter implements the ResponseWriter interface
 type ResponseWriter struct {
 	header http.Header
 	code   int
 	buffer *bytes.Buffer
+
+	mu        sync.Mutex
+	max       int64
+	overflow  Overflow
+	dst       http.ResponseWriter
+	file      *os.File
+	spilled   int64
+	sent      int64
+	reading   bool
+	committed bool
+	abandoned bool
+	stats     BufferStats
+
+	compress *Compression
+	encoding string
+	enc      io.WriteCloser
 }
 
 //NewResponseWriter creates a new ResponseWriter
@@ -25,13 +69,29 @@ func NewResponseWriter() *ResponseWriter {
 	}
 }
 
-// Bytes resturn a slice with the current buffer.
+// NewLimitedResponseWriter creates a new ResponseWriter that holds at most
+// max bytes in memory, zero means no limit. When the body grows beyond max
+// it is handled as overflow says, dst is the client used by Stream.
+func NewLimitedResponseWriter(dst http.ResponseWriter, max int64, overflow Overflow) *ResponseWriter {
+	rw := NewResponseWriter()
+	rw.dst = dst
+	rw.max = max
+	rw.overflow = overflow
+	return rw
+}
+
+// Bytes resturn a slice with the current buffer. Bytes spilled to disk or
+// streamed to the client aren't returned.
 func (rw *ResponseWriter) Bytes() []byte {
 	return rw.buffer.Bytes()
 }
 
-// Len returns the length of the current buffer.
+// Len returns the length of the current buffer, including the bytes spilled
+// to disk.
 func (rw *ResponseWriter) Len() int {
+	if rw.file != nil {
+		return int(rw.spilled)
+	}
 	return rw.buffer.Len()
 }
 
@@ -50,19 +110,142 @@ func (rw *ResponseWriter) ResponseCode() int {
 	return rw.code
 }
 
+// status returns the response code sent to the client.
+func (rw *ResponseWriter) status() int {
+	if rw.code == 0 {
+		return http.StatusOK
+	}
+	return rw.code
+}
+
 // Write the response data.
 func (rw *ResponseWriter) Write(buf []byte) (int, error) {
-	return rw.buffer.Write(buf)
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.abandoned {
+		return 0, http.ErrHandlerTimeout
+	}
+	if rw.file == nil && !rw.committed && rw.max > 0 && int64(rw.buffer.Len()+len(buf)) > rw.max {
+		err := rw.spill()
+		if err != nil {
+			return 0, e.Forward(err)
+		}
+	}
+	if rw.committed {
+		return rw.stream(buf)
+	}
+	if rw.file != nil {
+		n, err := rw.file.Write(buf)
+		rw.spilled += int64(n)
+		rw.stats.Disk += int64(n)
+		return n, err
+	}
+	n, err := rw.buffer.Write(buf)
+	if l := int64(rw.buffer.Len()); l > rw.stats.Memory {
+		rw.stats.Memory = l
+	}
+	return n, err
+}
+
+// spill moves the buffer out of the memory.
+func (rw *ResponseWriter) spill() error {
+	if rw.overflow == Stream && rw.dst != nil {
+		rw.enc = rw.encoder(&counter{rw.dst, &rw.sent}, rw.max+1, rw.buffer.Bytes())
+		rw.writeHeader(rw.dst)
+		rw.committed = true
+		_, err := rw.stream(rw.buffer.Bytes())
+		rw.buffer.Reset()
+		return err
+	}
+	if rw.compress != nil && rw.header.Get("Content-Type") == "" {
+		rw.header.Set("Content-Type", http.DetectContentType(rw.buffer.Bytes()))
+	}
+	f, err := ioutil.TempFile("", "httprouter")
+	if err != nil {
+		return e.Forward(err)
+	}
+	rw.file = f
+	n, err := f.Write(rw.buffer.Bytes())
+	rw.spilled += int64(n)
+	rw.stats.Disk += int64(n)
+	rw.buffer.Reset()
+	return err
+}
+
+// stream writes buf to the client, compressed if needed.
+func (rw *ResponseWriter) stream(buf []byte) (int, error) {
+	var n int
+	var err error
+	if rw.enc != nil {
+		n, err = rw.enc.Write(buf)
+	} else {
+		n, err = rw.dst.Write(buf)
+		rw.sent += int64(n)
+	}
+	rw.stats.Streamed += int64(n)
+	return n, err
+}
+
+// counter counts the bytes written to w.
+type counter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
 }
 
 // Read reads the buffer to p slice and return the number of readen bytes our error.
 func (rw *ResponseWriter) Read(p []byte) (int, error) {
+	if rw.file != nil {
+		if !rw.reading {
+			_, err := rw.file.Seek(0, io.SeekStart)
+			if err != nil {
+				return 0, e.Forward(err)
+			}
+			rw.reading = true
+		}
+		return rw.file.Read(p)
+	}
 	return rw.buffer.Read(p)
 }
 
-// Copy the data from the ResponseWriter struct to the
-// ResponseWriter interface used in the http package.
-func (rw *ResponseWriter) Copy(dst http.ResponseWriter) error {
+// Stats returns how the body was buffered so far.
+func (rw *ResponseWriter) Stats() BufferStats {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.stats
+}
+
+// Sent returns the number of body bytes sent to the client.
+func (rw *ResponseWriter) Sent() int64 {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.sent
+}
+
+// Committed returns true if the headers were already sent to the client
+// because the body overflowed in Stream mode.
+func (rw *ResponseWriter) Committed() bool {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.committed
+}
+
+// abandon makes all further writes fail with http.ErrHandlerTimeout, drops
+// the temporary file and returns true if the headers were already sent.
+func (rw *ResponseWriter) abandon() bool {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.abandoned = true
+	rw.close()
+	return rw.committed
+}
+
+func (rw *ResponseWriter) writeHeader(dst http.ResponseWriter) {
 	header := dst.Header()
 	for k, v := range rw.header {
 		for _, item := range v {
@@ -72,20 +255,92 @@ func (rw *ResponseWriter) Copy(dst http.ResponseWriter) error {
 	if rw.code != 0 {
 		dst.WriteHeader(rw.code)
 	}
-	l := rw.buffer.Len()
-	n, err := dst.Write(rw.buffer.Bytes())
-	if err != nil {
-		return e.Forward(err)
+}
+
+// Copy the data from the ResponseWriter struct to the
+// ResponseWriter interface used in the http package.
+// If the response was already streamed Copy only flushes the compressor.
+func (rw *ResponseWriter) Copy(dst http.ResponseWriter) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if rw.committed {
+		if rw.enc == nil {
+			return nil
+		}
+		err := rw.enc.Close()
+		rw.enc = nil
+		if err != nil {
+			return e.Forward(err)
+		}
+		return nil
 	}
-	if n != l {
-		return e.New("didn't wrote all data")
+	var out io.Writer = &counter{dst, &rw.sent}
+	enc := rw.encoder(out, int64(rw.Len()), rw.buffer.Bytes())
+	if enc != nil {
+		out = enc
+	}
+	rw.writeHeader(dst)
+	if rw.file != nil {
+		_, err := rw.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return e.Forward(err)
+		}
+		n, err := io.Copy(out, rw.file)
+		if err != nil {
+			return e.Forward(err)
+		}
+		if n != rw.spilled {
+			return e.New("didn't wrote all data")
+		}
+	} else {
+		l := rw.buffer.Len()
+		n, err := out.Write(rw.buffer.Bytes())
+		if err != nil {
+			return e.Forward(err)
+		}
+		if n != l {
+			return e.New("didn't wrote all data")
+		}
+	}
+	if enc != nil {
+		err := enc.Close()
+		if err != nil {
+			return e.Forward(err)
+		}
 	}
 	return nil
 }
 
-// Reset the buffer.
+// Reset the buffer. The headers and the bytes already streamed to the client
+// can't be taken back.
 func (rw *ResponseWriter) Reset() {
 	rw.code = 0
 	rw.header = make(map[string][]string)
 	rw.buffer = bytes.NewBuffer([]byte{})
+	rw.Close()
+}
+
+// Close removes the temporary file used when the body spilled to disk.
+func (rw *ResponseWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.close()
+}
+
+func (rw *ResponseWriter) close() error {
+	if rw.file == nil {
+		return nil
+	}
+	name := rw.file.Name()
+	err := rw.file.Close()
+	rw.file = nil
+	rw.spilled = 0
+	rw.reading = false
+	if er := os.Remove(name); er != nil && err == nil {
+		err = er
+	}
+	if err != nil {
+		return e.Forward(err)
+	}
+	return nil
 }
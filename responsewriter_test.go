@@ -6,6 +6,9 @@ package httprouter
 
 import (
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 )
 
@@ -69,4 +72,81 @@ func TestResponseWriter(t *testing.T) {
 	if string(buf) != str {
 		t.Fatal("read failed", string(buf))
 	}
-}
\ No newline at end of file
+}
+func TestResponseWriterSpill(t *testing.T) {
+	str := "catotos"
+	rw := NewLimitedResponseWriter(nil, 4, Stream)
+	for i := 0; i < 3; i++ {
+		_, err := rw.Write([]byte(str))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rw.file == nil {
+		t.Fatal("didn't spill to disk")
+	}
+	name := rw.file.Name()
+	if rw.Len() != 3*len(str) {
+		t.Fatal("invalid length", rw.Len())
+	}
+	if len(rw.Bytes()) != 0 {
+		t.Fatal("data still in memory")
+	}
+	stats := rw.Stats()
+	if stats.Memory != 0 || stats.Disk != int64(3*len(str)) || stats.Streamed != 0 {
+		t.Fatal("wrong stats", stats)
+	}
+
+	dst := httptest.NewRecorder()
+	err := rw.Copy(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Body.String() != str+str+str {
+		t.Fatal("copy failed", dst.Body.String())
+	}
+	err = rw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatal("temporary file not removed")
+	}
+}
+
+func TestResponseWriterStream(t *testing.T) {
+	str := "catotos"
+	dst := httptest.NewRecorder()
+	rw := NewLimitedResponseWriter(dst, 10, Stream)
+	rw.Header().Set("foo", "bar")
+	rw.WriteHeader(http.StatusCreated)
+	for i := 0; i < 3; i++ {
+		_, err := rw.Write([]byte(str))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !rw.Committed() {
+		t.Fatal("not committed")
+	}
+	if dst.Code != http.StatusCreated || dst.Header().Get("foo") != "bar" {
+		t.Fatal("headers not committed", dst.Code, dst.Header())
+	}
+	err := rw.Copy(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Body.String() != str+str+str {
+		t.Fatal("stream failed", dst.Body.String())
+	}
+	stats := rw.Stats()
+	if stats.Memory != int64(len(str)) || stats.Disk != 0 || stats.Streamed != int64(3*len(str)) {
+		t.Fatal("wrong stats", stats)
+	}
+	if rw.abandon() != true {
+		t.Fatal("abandon didn't report the commit")
+	}
+	if _, err := rw.Write([]byte(str)); err != http.ErrHandlerTimeout {
+		t.Fatal("write after abandon", err)
+	}
+}
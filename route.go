@@ -0,0 +1,85 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import "net/http"
+
+// Route is a handle registered in the router. The exported fields are the
+// per-route options and may be set after the registration, before the router
+// starts serving requests.
+type Route struct {
+	router *Router
+	method string
+	path   string
+	i18n   bool
+	handle http.HandlerFunc
+	group  *Group
+
+	// paths are the localized paths of the route by language.
+	paths map[string]string
+	// base is the route localized by this one, and lang the language of
+	// its path.
+	base *Route
+	lang string
+
+	// Name identifies the route, for example to invalidate its cached
+	// responses.
+	Name string
+
+	// ETag enables the automatic ETag and conditional GET handling for GET
+	// and HEAD requests of this route.
+	ETag ETagMode
+
+	// RateLimit limits the requests to the route. If it is nil the limiter
+	// of the group is used.
+	RateLimit *RateLimiter
+
+	// MaxBodySize is the maximum size in bytes of the request bodies. Zero
+	// uses the limit of the group or of the router, a negative value
+	// disables the limit.
+	MaxBodySize int64
+
+	// Langs are the languages the i18n route is available in. If it is nil
+	// the route is available in all SupportedLangs. The requests for other
+	// languages are handled as Router.LangUnavailable says.
+	Langs []string
+}
+
+// Method returns the request method of the route.
+func (rt *Route) Method() string {
+	return rt.method
+}
+
+// Path returns the path pattern of the route, as it was registered.
+func (rt *Route) Path() string {
+	return rt.path
+}
+
+// Group returns the group the route was registered with, or nil.
+func (rt *Route) Group() *Group {
+	return rt.group
+}
+
+// langSet returns the languages of the route.
+func (rt *Route) langSet() map[string]struct{} {
+	langs := make(map[string]struct{}, len(rt.Langs))
+	for _, lang := range rt.Langs {
+		langs[lang] = struct{}{}
+	}
+	return langs
+}
+
+// rateLimiter returns the limiter of the route or of its groups.
+func (rt *Route) rateLimiter() *RateLimiter {
+	if rt.RateLimit != nil {
+		return rt.RateLimit
+	}
+	for g := rt.group; g != nil; g = g.parent {
+		if g.RateLimit != nil {
+			return g.RateLimit
+		}
+	}
+	return nil
+}
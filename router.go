@@ -80,7 +80,6 @@ package httprouter
 import (
 	"context"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -153,6 +152,15 @@ type Router struct {
 	// RedirectTrailingSlash is independent of this option.
 	RedirectFixedPath bool
 
+	// Optional redirect and rewrite rules. They are applied before the
+	// request is routed.
+	Rules *Rules
+
+	// Optional policy of the trailing slash, fixed path and language
+	// redirects. If it is not set, the redirects are permanent, but the
+	// language redirects of requests other than GET use the 307 status code.
+	RedirectPolicy *RedirectPolicy
+
 	// If enabled, the router checks if another method is allowed for the
 	// current route, if the current request can not be routed.
 	// If this is the case, the request is answered with 'Method Not Allowed'
@@ -185,12 +193,107 @@ type Router struct {
 	// is called.
 	MethodNotAllowed http.Handler
 
+	// Maximum size in bytes of the request bodies, zero means no limit. The
+	// routes and the groups may have their own limit, see Route.MaxBodySize.
+	// The handle reading beyond the limit gets an error and the response is
+	// replaced by the 413 (Request Entity Too Large) response.
+	MaxBodySize int64
+
+	// Configurable http.Handler which is called when the request body is
+	// larger than the limit of the route. If it is not set, http.Error with
+	// http.StatusRequestEntityTooLarge is used.
+	BodyTooLarge http.Handler
+
+	// Cross-Origin Resource Sharing policy. If it is set the preflight
+	// requests are answered, when HandleOPTIONS is true, and the responses
+	// to allowed origins get the CORS headers.
+	CORS *CORS
+
+	// Function called with the errors returned by the handles registered
+	// with HandleErr. The response written by the handle was discarded. If it
+	// is not set, the error response with the code returned by ErrorStatus is
+	// sent, see ProblemDetails.
+	ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+	// Status codes of the errors returned by the handles, see ErrorStatus.
+	ErrorCodes []ErrorCode
+
+	// If enabled, the error responses generated by the router, and the ones
+	// of the errors returned by the handles, are RFC 7807 problem details
+	// sent as application/problem+json, HTML or plain text as negotiated
+	// with the Accept header. They have the request ID, see
+	// RequestIDHeader. Otherwise they are plain text.
+	ProblemDetails bool
+
 	// Function to handle panics recovered from http handlers.
 	// It should be used to generate a error page and return the http error code
 	// 500 (Internal Server Error).
-	// The handler can be used to keep your server from crashing because of
-	// unrecovered panics.
-	PanicHandler func(http.ResponseWriter, *http.Request, interface{})
+	// The panics are always recovered, in the handles run in the ServeHTTP
+	// goroutine and in the ones run in their own goroutine, and logged with
+	// the stack trace. The exception is http.ErrAbortHandler, that aborts
+	// the response and is panicked again in the ServeHTTP goroutine. If it is not set, the response written by the handle
+	// is discarded and the 500 error is sent, see ProblemDetails.
+	PanicHandler func(http.ResponseWriter, *http.Request, *PanicInfo)
+
+	// Function called when the context returned by Context reaches its
+	// deadline before the handle returns. It receives the request and the
+	// context error. If it is not set, http.Error with
+	// http.StatusServiceUnavailable is used.
+	TimeoutHandler func(http.ResponseWriter, *http.Request, error)
+
+	// Function called when the context returned by Context is canceled, or
+	// done for any reason other than its deadline, before the handle
+	// returns. It receives the request and the context error. If it is not
+	// set, http.Error with http.StatusInternalServerError is used.
+	CanceledHandler func(http.ResponseWriter, *http.Request, error)
+
+	// Maximum number of response bytes buffered in memory for each request.
+	// Zero means no limit.
+	MaxBufferSize int64
+
+	// What to do with the response when it grows beyond MaxBufferSize:
+	// spill it to a temporary file or stream it to the client.
+	BufferOverflow Overflow
+
+	// An optional function called after each response is sent with the
+	// number of bytes buffered for it.
+	BufferMetrics func(*http.Request, BufferStats)
+
+	// Compression of the responses with gzip or deflate, negotiated with the
+	// Accept-Encoding header. If nil the responses are sent as they are.
+	Compression *Compression
+
+	// An optional cache of the responses. See Cache for the responses that
+	// are stored.
+	Cache *Cache
+
+	// Logger of the requests served by the router. New sets it to the logger
+	// returned by NewSlogLogger, nil turns the access log off.
+	AccessLog AccessLogger
+
+	// Optional collector of per-route metrics. See Metrics to serve them.
+	Metrics *Metrics
+
+	// Optional tracer of the requests. If set the router reads the W3C Trace
+	// Context headers traceparent and tracestate, stores the span context in
+	// the request context, see SpanFromContext, and reports the span events
+	// to the tracer.
+	Tracer Tracer
+
+	// If not empty the router assigns an ID to each request, stores it in
+	// the request context, see RequestID, and sends it back in the response
+	// header with this name, usually X-Request-ID. The ID is also in the
+	// access log and in the panic and timeout reports.
+	RequestIDHeader string
+
+	// If enabled, the request ID received in the RequestIDHeader is used
+	// when it is valid, see ValidRequestID. Otherwise a new ID is always
+	// generated.
+	TrustRequestID bool
+
+	// Optional function that generates the request IDs. If it is not set
+	// NewRequestID is used.
+	GenerateRequestID func() string
 
 	// Context is a function to insert a new context just after the http request
 	// context and before the router params.
@@ -202,6 +305,26 @@ type Router struct {
 	// lang in the url or the lang selected is not available
 	// for that resource.
 	DefaultLang string
+	// Fallback chains of the languages that aren't supported, tried before
+	// the RFC 4647 lookup. For example {"pt-BR": {"pt-PT", "pt"}}.
+	LangFallback map[string][]string
+	// Resolvers of the language requested by the client, tried in order when
+	// the path has no language prefix. If it is nil, AcceptLanguage is used.
+	LangResolvers []LangResolver
+	// What to do when the path of an i18n route has no language prefix:
+	// redirect to the path with the prefix, the default, or serve in place.
+	LangStrategy LangStrategy
+	// If it is set, the language is stored in a cookie when the client is
+	// redirected to a language prefix or visits a path with the prefix. The
+	// cookie takes priority over LangResolvers.
+	LangCookie *LangCookie
+	// If enabled, the responses of the i18n routes have the Link headers
+	// with the URLs in the other languages, see Alternates.
+	AlternateLinks bool
+	// What to do when the language in the path prefix isn't one of the
+	// languages of the route, see Route.Langs. The default is to redirect to
+	// the best language of the route.
+	LangUnavailable LangUnavailable
 }
 
 // Make sure the Router conforms with the http.Handler interface
@@ -215,6 +338,7 @@ func New() *Router {
 		RedirectFixedPath:      true,
 		HandleMethodNotAllowed: true,
 		HandleOPTIONS:          true,
+		AccessLog:              NewSlogLogger(),
 		Context: func(ctx context.Context) (context.Context, context.CancelFunc) {
 			return ctx, nil
 		},
@@ -248,38 +372,38 @@ func (r *Router) putParams(ps *Params) {
 }
 
 // GET is a shortcut for router.Handle(http.MethodGet, path, i18n, handle)
-func (r *Router) GET(path string, i18n bool, handle http.HandlerFunc) {
-	r.Handle(http.MethodGet, path, i18n, handle)
+func (r *Router) GET(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodGet, path, i18n, handle)
 }
 
 // HEAD is a shortcut for router.Handle(http.MethodHead, path, i18n, handle)
-func (r *Router) HEAD(path string, i18n bool, handle http.HandlerFunc) {
-	r.Handle(http.MethodHead, path, i18n, handle)
+func (r *Router) HEAD(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodHead, path, i18n, handle)
 }
 
 // OPTIONS is a shortcut for router.Handle(http.MethodOptions, path, i18n, handle)
-func (r *Router) OPTIONS(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodOptions, path, false, handle)
+func (r *Router) OPTIONS(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodOptions, path, false, handle)
 }
 
 // POST is a shortcut for router.Handle(http.MethodPost, path, i18n, handle)
-func (r *Router) POST(path string, i18n bool, handle http.HandlerFunc) {
-	r.Handle(http.MethodPost, path, i18n, handle)
+func (r *Router) POST(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodPost, path, i18n, handle)
 }
 
 // PUT is a shortcut for router.Handle(http.MethodPut, path, i18n, handle)
-func (r *Router) PUT(path string, i18n bool, handle http.HandlerFunc) {
-	r.Handle(http.MethodPut, path, i18n, handle)
+func (r *Router) PUT(path string, i18n bool, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodPut, path, i18n, handle)
 }
 
 // PATCH is a shortcut for router.Handle(http.MethodPatch, path, i18n, handle)
-func (r *Router) PATCH(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodPatch, path, false, handle)
+func (r *Router) PATCH(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodPatch, path, false, handle)
 }
 
 // DELETE is a shortcut for router.Handle(http.MethodDelete, path, i18n, handle)
-func (r *Router) DELETE(path string, handle http.HandlerFunc) {
-	r.Handle(http.MethodDelete, path, false, handle)
+func (r *Router) DELETE(path string, handle http.HandlerFunc) *Route {
+	return r.Handle(http.MethodDelete, path, false, handle)
 }
 
 // Handle registers a new request handle with the given path and method.
@@ -290,7 +414,9 @@ func (r *Router) DELETE(path string, handle http.HandlerFunc) {
 // This function is intended for bulk loading and to allow the usage of less
 // frequently used, non-standardized or custom methods (e.g. for internal
 // communication with a proxy).
-func (r *Router) Handle(method, path string, i18n bool, handle http.HandlerFunc) {
+//
+// The returned Route can be used to set the per-route options.
+func (r *Router) Handle(method, path string, i18n bool, handle http.HandlerFunc) *Route {
 	if method == "" {
 		panic("method must not be empty")
 	}
@@ -315,6 +441,15 @@ func (r *Router) Handle(method, path string, i18n bool, handle http.HandlerFunc)
 
 	root.addRoute(path, i18n, handle)
 
+	rt := &Route{
+		router: r,
+		method: method,
+		path:   path,
+		i18n:   i18n,
+		handle: handle,
+	}
+	root.leaf(path).route = rt
+
 	// Update maxParams
 	if pc := countParams(path); pc > r.maxParams {
 		r.maxParams = pc
@@ -327,12 +462,14 @@ func (r *Router) Handle(method, path string, i18n bool, handle http.HandlerFunc)
 			return &ps
 		}
 	}
+
+	return rt
 }
 
 // Handler is an adapter which allows the usage of an http.Handler as a
 // request handle.
-func (r *Router) Handler(method, path string, i18n bool, handler http.Handler) {
-	r.Handle(method, path, i18n,
+func (r *Router) Handler(method, path string, i18n bool, handler http.Handler) *Route {
+	return r.Handle(method, path, i18n,
 		func(w http.ResponseWriter, req *http.Request) {
 			handler.ServeHTTP(w, req)
 		},
@@ -341,8 +478,8 @@ func (r *Router) Handler(method, path string, i18n bool, handler http.Handler) {
 
 // HandlerFunc is an adapter which allows the usage of an http.HandlerFunc as a
 // request handle.
-func (r *Router) HandlerFunc(method, path string, i18n bool, handler http.HandlerFunc) {
-	r.Handler(method, path, i18n, handler)
+func (r *Router) HandlerFunc(method, path string, i18n bool, handler http.HandlerFunc) *Route {
+	return r.Handler(method, path, i18n, handler)
 }
 
 // ServeFiles serves files from the given file system root.
@@ -368,9 +505,24 @@ func (r *Router) ServeFiles(path string, root http.FileSystem) {
 	})
 }
 
-func (r *Router) recv(w http.ResponseWriter, req *http.Request) {
-	if rcv := recover(); rcv != nil {
-		r.PanicHandler(w, req, rcv)
+func (r *Router) newResponseWriter(rw http.ResponseWriter, req *http.Request) *ResponseWriter {
+	w := NewLimitedResponseWriter(rw, r.MaxBufferSize, r.BufferOverflow)
+	if r.Compression != nil {
+		w.compress = r.Compression
+		w.encoding = r.Compression.negotiate(req)
+	}
+	return w
+}
+
+// finish runs the steps that need the complete response of the route. The
+// ETag is set before the response is cached, so the cached response has it.
+func (r *Router) finish(w *ResponseWriter, req *http.Request, rt *Route) {
+	etag := setETag(w, req, rt.ETag)
+	if r.Cache != nil {
+		r.Cache.store(w, req, rt)
+	}
+	if etag {
+		conditional(w, req)
 	}
 }
 
@@ -380,28 +532,8 @@ func (r *Router) recv(w http.ResponseWriter, req *http.Request) {
 // values. Otherwise the third return value indicates whether a redirection to
 // the same path with an extra / without the trailing slash should be performed.
 func (r *Router) Lookup(method, path string) (http.HandlerFunc, Params, bool) {
-	trailSlash := false
-	if path[len(path)-1] == '/' {
-		trailSlash = true
-	}
-	psplit := splitPath(path)
-	if r.DefaultLang != "" {
-		if len(psplit) > 0 {
-			candidate := psplit[0]
-			_, found := r.SupportedLangs[candidate]
-			if found {
-				if len(psplit) > 1 {
-					path = "/" + filepath.Join(psplit[1:]...)
-				}
-			}
-		}
-		if trailSlash {
-			path += "/"
-		}
-	}
-
 	if root := r.trees[method]; root != nil {
-		handle, ps, _, tsr := root.getValue(path, r.getParams)
+		handle, ps, _, tsr, _ := r.getValue(root, path)
 		if handle == nil {
 			r.putParams(ps)
 			return nil, nil, tsr
@@ -437,7 +569,9 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 				continue
 			}
 
-			handle, _, _, _ := r.trees[method].getValue(path, nil)
+			// Same lookup of ServeHTTP, with the language prefixes.
+			handle, ps, _, _, _ := r.getValue(r.trees[method], path)
+			r.putParams(ps)
 			if handle != nil {
 				// Add request method to list of allowed methods
 				allowed = append(allowed, method)
@@ -466,31 +600,125 @@ func (r *Router) allowed(path, reqMethod string) (allow string) {
 
 // ServeHTTP makes the router implement the http.Handler interface.
 func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	start := time.Now()
+	if r.RequestIDHeader != "" {
+		req = r.requestID(rw, req)
+	}
+	if r.CORS != nil && !isPreflight(req) {
+		r.CORS.decorate(rw.Header(), req)
+	}
+	w := r.newResponseWriter(rw, req)
+	var matched *Route
+	if r.AccessLog != nil || r.Metrics != nil {
+		entry := newAccessEntry(req, time.Now())
+		defer func() {
+			entry.Latency = time.Since(entry.Time)
+			entry.Status = w.status()
+			entry.Bytes = w.Sent()
+			if matched != nil {
+				entry.Pattern = matched.path
+			}
+			entry.Lang = ContentLang(req)
+			entry.RequestID = RequestID(req)
+			if r.AccessLog != nil {
+				r.AccessLog.Log(entry)
+			}
+			if r.Metrics != nil {
+				r.Metrics.end(entry, matched != nil)
+			}
+		}()
+	}
+	var span Span
+	if r.Tracer != nil {
+		req, span = r.startSpan(req)
+		defer func() {
+			span.End(w.status())
+		}()
+	}
+	// aborted is set if the handle aborted the response with
+	// http.ErrAbortHandler, nothing is sent then.
+	aborted := false
 	defer func() {
-		log.InfoLevel().Tag("httprouter", "statistics").Printf("Method=%v, Path=%v, Execution=%v", req.Method, req.URL.Path, time.Since(start))
+		if !aborted {
+			w.Copy(rw)
+		}
+		w.Close()
+		if r.BufferMetrics != nil {
+			r.BufferMetrics(req, w.Stats())
+		}
 	}()
-	w := NewResponseWriter()
+
 	defer func() {
-		w.Copy(rw)
+		if rcv := recover(); rcv != nil {
+			if rcv == errAbortHandler {
+				// net/http aborts the connection.
+				aborted = true
+				panic(rcv)
+			}
+			r.recovered(w, req, newPanicInfo(rcv, matched, req))
+		}
 	}()
 
+	if r.Rules != nil {
+		var done bool
+		req, done = r.applyRules(w, req)
+		if done {
+			return
+		}
+	}
+
 	path := req.URL.Path
 	rawpath := path
 
-	if r.PanicHandler != nil {
-		defer r.recv(w, req)
-	}
-
 	if root := r.trees[req.Method]; root != nil {
-		if handle, ps, i18n, tsr := root.getValue(path, r.getParams); handle != nil {
-			if r.DefaultLang != "" && i18n == true {
-				req, path = r.selectLang(w, req)
+		if handle, ps, rt, tsr, lp := r.getValue(root, path); handle != nil {
+			matched = rt
+			if r.Metrics != nil {
+				r.Metrics.begin(req.Method, rt.path)
+			}
+			if span != nil {
+				span.Match(rt.path)
 			}
 			if ps != nil {
 				req = req.WithContext(context.WithValue(req.Context(), "Params", *ps))
+			}
+			if r.DefaultLang != "" && rt.i18n == true {
+				var redirected bool
+				req, redirected = r.selectLang(w, req, rt, lp)
+				if redirected {
+					r.putParams(ps)
+					return
+				}
+			}
+			if l := rt.rateLimiter(); l != nil && !l.allow(w, req) {
+				r.httpError(w, req, http.StatusTooManyRequests, "")
+				r.putParams(ps)
+				return
+			}
+			if r.Cache != nil && r.Cache.load(w, req) {
+				if setETag(w, req, rt.ETag) {
+					conditional(w, req)
+				}
+				r.putParams(ps)
+				return
+			}
+			body, ok := r.limitBody(w, req, rt)
+			if !ok {
+				r.putParams(ps)
+				return
+			}
+			if ps != nil {
+				if span != nil {
+					span.HandlerStart()
+				}
 				handle(w, req)
+				if span != nil {
+					span.HandlerEnd()
+				}
 				r.putParams(ps)
+				if r.checkBody(w, req, body) {
+					return
+				}
+				r.finish(w, req, rt)
 			} else {
 				// Put in the context all parameters
 				ctx := req.Context()
@@ -503,67 +731,87 @@ func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 				}
 
 				req = req.WithContext(context.WithValue(req.Context(), "Params", nil))
-				s := make(chan struct{})
+				// The handle goroutine sends nil when it returns or the
+				// recovered panic.
+				s := make(chan *PanicInfo, 1)
+				if span != nil {
+					span.HandlerStart()
+				}
 
 				go func(w http.ResponseWriter, req *http.Request) {
-					if r.PanicHandler != nil {
-						defer func() {
-							if rcv := recover(); rcv != nil {
-								r.PanicHandler(w, req, rcv)
-								s <- struct{}{}
-							}
-						}()
-					}
+					defer func() {
+						if rcv := recover(); rcv != nil {
+							s <- newPanicInfo(rcv, rt, req)
+						}
+					}()
 					// Call handler
 					handle(w, req)
-					s <- struct{}{}
+					s <- nil
 				}(w, req)
 
 				select {
-				case <-s:
+				case p := <-s:
 					// Signal telling that the handle was executed.
+					if span != nil {
+						span.HandlerEnd()
+					}
+					if p != nil {
+						if p.Value == errAbortHandler {
+							panic(p.Value)
+						}
+						r.recovered(w, req, p)
+						return
+					}
+					if r.checkBody(w, req, body) {
+						return
+					}
+					r.finish(w, req, rt)
 					return
 				case <-ctx.Done():
-					w.Reset()
-					switch ctx.Err() {
+					// The handle may still be writing to w, so the reply is
+					// built in a fresh buffer.
+					if w.abandon() {
+						log.DebugLevel().Tag("httprouter").Printf("Context send a signal after the response was committed. RequestID=%v Method=%v Path=%v Err: %v.", RequestID(req), req.Method, req.URL.Path, ctx.Err())
+						return
+					}
+					w = r.newResponseWriter(rw, req)
+					err := ctx.Err()
+					if span != nil {
+						span.Timeout(err)
+					}
+					switch err {
 					case context.Canceled:
-						http.Error(w,
-							"Context canceled",
-							http.StatusInternalServerError,
-						)
-						log.DebugLevel().Tag("httprouter").Printf("Context send a signal. Handler terminated. Method=%v Path=%v Err: context canceled.", req.Method, req.URL.Path)
+						log.DebugLevel().Tag("httprouter").Printf("Context send a signal. Handler terminated. RequestID=%v Method=%v Path=%v Err: context canceled.", RequestID(req), req.Method, req.URL.Path)
+						if r.CanceledHandler != nil {
+							r.CanceledHandler(w, req, err)
+							return
+						}
+						r.httpError(w, req, http.StatusInternalServerError, "Context canceled")
 					case context.DeadlineExceeded:
-						// TODO: Custom error???
-						http.Error(w,
-							http.StatusText(http.StatusRequestTimeout),
-							http.StatusRequestTimeout,
-						)
-						log.DebugLevel().Tag("httprouter").Printf("Context send a signal. Handler terminated. Method=%v Path=%v Err: deadline exceeded.", req.Method, req.URL.Path)
+						log.DebugLevel().Tag("httprouter").Printf("Context send a signal. Handler terminated. RequestID=%v Method=%v Path=%v Err: deadline exceeded.", RequestID(req), req.Method, req.URL.Path)
+						if r.TimeoutHandler != nil {
+							r.TimeoutHandler(w, req, err)
+							return
+						}
+						r.httpError(w, req, http.StatusServiceUnavailable, "")
 					default:
-						http.Error(w,
-							http.StatusText(http.StatusInternalServerError),
-							http.StatusInternalServerError,
-						)
-						log.DebugLevel().Tag("httprouter").Printf("Context send a signal. Handler terminated. Method=%v Path=%v Err: unknown.", req.Method, req.URL.Path)
+						log.DebugLevel().Tag("httprouter").Printf("Context send a signal. Handler terminated. RequestID=%v Method=%v Path=%v Err: unknown.", RequestID(req), req.Method, req.URL.Path)
+						if r.CanceledHandler != nil {
+							r.CanceledHandler(w, req, err)
+							return
+						}
+						r.httpError(w, req, http.StatusInternalServerError, "")
 					}
 				}
 			}
 			return
 		} else if req.Method != http.MethodConnect && path != "/" {
-			// Moved Permanently, request with GET method
-			code := http.StatusMovedPermanently
-			if req.Method != http.MethodGet {
-				// Permanent Redirect, request with same method
-				code = http.StatusPermanentRedirect
-			}
-
 			if tsr && r.RedirectTrailingSlash {
 				if len(rawpath) > 1 && rawpath[len(rawpath)-1] == '/' {
-					req.URL.Path = rawpath[:len(rawpath)-1]
+					r.redirect(w, req, TrailingSlashRedirect, rawpath[:len(rawpath)-1])
 				} else {
-					req.URL.Path = rawpath + "/"
+					r.redirect(w, req, TrailingSlashRedirect, rawpath+"/")
 				}
-				http.Redirect(w, req, req.URL.String(), code)
 				return
 			}
 
@@ -574,8 +822,7 @@ func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 					r.RedirectTrailingSlash,
 				)
 				if found {
-					req.URL.Path = fixedPath
-					http.Redirect(w, req, req.URL.String(), code)
+					r.redirect(w, req, FixedPathRedirect, fixedPath)
 					return
 				}
 			}
@@ -586,6 +833,10 @@ func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		// Handle OPTIONS requests
 		if allow := r.allowed(path, http.MethodOptions); allow != "" {
 			w.Header().Set("Allow", allow)
+			if r.CORS != nil && isPreflight(req) {
+				r.CORS.preflight(w, req, allow)
+				return
+			}
 			if r.GlobalOPTIONS != nil {
 				r.GlobalOPTIONS.ServeHTTP(w, req)
 			}
@@ -597,67 +848,81 @@ func (r *Router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			if r.MethodNotAllowed != nil {
 				r.MethodNotAllowed.ServeHTTP(w, req)
 			} else {
-				http.Error(w,
-					http.StatusText(http.StatusMethodNotAllowed),
-					http.StatusMethodNotAllowed,
-				)
+				r.httpError(w, req, http.StatusMethodNotAllowed, "")
 			}
 			return
 		}
 	}
 
 	// Handle 404
-	if r.NotFound != nil {
+	r.notFound(w, req)
+}
+
+func (r *Router) notFound(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case r.NotFound != nil:
 		r.NotFound.ServeHTTP(w, req)
-	} else {
+	case r.ProblemDetails:
+		r.httpError(w, req, http.StatusNotFound, "")
+	default:
 		http.NotFound(w, req)
 	}
 }
 
-func (r *Router) selectLang(w http.ResponseWriter, req *http.Request) (*http.Request, string) {
-	selectedLang := r.DefaultLang
-	path := req.URL.Path
-
-	if lang := r.acceptedLanguage(req); lang != "" {
-		selectedLang = lang
-	}
-
-	if req.URL.String() != "*" {
-		trailSlash := false
-		if len(path) > 0 && path[len(path)-1] == '/' {
-			trailSlash = true
+// selectLang puts the language of the prefix lp in the request context. If
+// the path has no language prefix, the language is found by LangResolvers,
+// or it is the default one, and unless LangStrategy is ServeLang the client
+// is redirected to the path with the language. The responses with the
+// resolved language vary with the headers read by the resolvers, see
+// LangVary, and the served ones have the Content-Language header, the handle
+// may change it. If the prefix isn't the supported language it matched, the
+// client is redirected to the path with the supported language. If the
+// route isn't available in the language, see Route.Langs, the best language
+// of the route is used. The client is redirected as well if the path isn't
+// the localized path of the route for the language, see Route.Localize. It
+// returns true if the response was sent.
+func (r *Router) selectLang(w http.ResponseWriter, req *http.Request, rt *Route, lp langPrefix) (*http.Request, bool) {
+	if lp.lang == "" {
+		if req.URL.String() == "*" {
+			return req.WithContext(context.WithValue(req.Context(), "UASelectedLang", r.DefaultLang)), false
 		}
-		psplit := splitPath(path)
-		if len(psplit) > 0 {
-			candidate := psplit[0]
-			// TODO: Search for language of the same family.
-			// Example: pt-BR not found, search for another, maybe pt-PT or pt.
-			_, found := r.SupportedLangs[candidate]
-			if found {
-				selectedLang = candidate
-				if len(psplit) > 1 {
-					path = "/" + filepath.Join(psplit[1:]...)
-					if trailSlash {
-						path += "/"
-					}
-				} else if len(psplit) == 1 {
-					path = "/"
-				}
-			} else {
-				req = req.WithContext(context.WithValue(req.Context(), "UASelectedLang", selectedLang))
-				redirLang(w, req, selectedLang)
-				return req, path
-			}
-		} else {
-			req = req.WithContext(context.WithValue(req.Context(), "UASelectedLang", selectedLang))
-			redirLang(w, req, selectedLang)
-			return req, path
+		selectedLang := r.DefaultLang
+		if lang := r.resolveLang(req); lang != "" {
+			selectedLang = lang
+		}
+		selectedLang = r.availableLang(rt, selectedLang)
+		if selectedLang == "" {
+			r.notFound(w, req)
+			return req, true
 		}
+		req = req.WithContext(context.WithValue(req.Context(), "UASelectedLang", selectedLang))
+		r.varyLang(w.Header())
+		if r.LangStrategy == ServeLang {
+			w.Header().Set("Content-Language", selectedLang)
+			return r.setAlternates(w, req, rt, req.URL.Path, lp.pathLang), false
+		}
+		if r.LangCookie != nil {
+			r.LangCookie.set(w, req, selectedLang)
+		}
+		r.redirLang(w, req, rt, lp.pathLang, selectedLang)
+		return req, true
 	}
-
-	req = req.WithContext(context.WithValue(req.Context(), "UASelectedLang", selectedLang))
-
-	return req, path
+	lang := r.availableLang(rt, lp.lang)
+	if lang == "" || (lang != lp.lang && r.LangUnavailable == NotFoundLang) {
+		r.notFound(w, req)
+		return req, true
+	}
+	req = req.WithContext(context.WithValue(req.Context(), "UASelectedLang", lang))
+	if r.LangCookie != nil {
+		r.LangCookie.set(w, req, lp.lang)
+	}
+	serve := lang != lp.lang && r.LangUnavailable == ServeAvailableLang
+	if (lp.tag != lang || rt.pattern(lang) != rt.pattern(lp.pathLang)) && !serve {
+		r.redirect(w, req, LangRedirect, "/"+lang+rt.localPath(lang, lp.pathLang, lp.rest, Parameters(req)))
+		return req, true
+	}
+	w.Header().Set("Content-Language", lang)
+	return r.setAlternates(w, req, rt, lp.rest, lp.pathLang), false
 }
 
 // PathExist returns true if a path exist. If the path
@@ -690,42 +955,8 @@ func ContentLang(req *http.Request) string {
 	return ""
 }
 
-func (r *Router) acceptedLanguage(req *http.Request) string {
-	params := req.Header.Get("Accept-Language")
-	if params == "" {
-		return ""
-	}
-	params = strings.ToLower(params)
-	parsed, err := ParseLang(params)
-	if err != nil {
-		return ""
-	}
-	param := parsed.FindBest(r.SupportedLangs)
-	if param == "" {
-		return ""
-	}
-	return param
-}
-
-func redirLang(w http.ResponseWriter, req *http.Request, lang string) {
-	code := 301
-	if req.Method != "GET" {
-		code = 307
-	}
-	req.URL.Path = "/" + lang + req.URL.Path
-	http.Redirect(w, req, req.URL.String(), code)
-}
-
-func splitPath(path string) []string {
-	p := strings.Split(path, "/")
-	psplit := make([]string, 0, len(p))
-	for _, elem := range p {
-		if elem == "" {
-			continue
-		}
-		psplit = append(psplit, strings.TrimSpace(elem))
-	}
-	return psplit
+func (r *Router) redirLang(w http.ResponseWriter, req *http.Request, rt *Route, pathLang, lang string) {
+	r.redirect(w, req, LangRedirect, "/"+lang+rt.localPath(lang, pathLang, req.URL.Path, Parameters(req)))
 }
 
 func find(name, path string, n *node) bool {
@@ -496,8 +496,8 @@ func TestRouterPanicHandler(t *testing.T) {
 	router := New()
 	panicHandled := false
 
-	router.PanicHandler = func(rw http.ResponseWriter, r *http.Request, p interface{}) {
-		panicHandled = true
+	router.PanicHandler = func(rw http.ResponseWriter, r *http.Request, p *PanicInfo) {
+		panicHandled = p.Value == "oops!" && p.Pattern == "/user/:name" && len(p.Stack) > 0
 	}
 
 	router.Handle("PUT", "/user/:name", false, func(_ http.ResponseWriter, _ *http.Request) {
@@ -701,6 +701,104 @@ func TestDeadlineContext(t *testing.T) {
 	}
 }
 
+func TestTimeoutHandler(t *testing.T) {
+	router := New()
+
+	router.Context = func(in context.Context) (context.Context, context.CancelFunc) {
+		return context.WithTimeout(in, time.Millisecond*50)
+	}
+	router.Handle("GET", "/deadline", false, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/deadline", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Wrong response http code: %v", w.Code)
+	}
+
+	var got error
+	router.TimeoutHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		got = err
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("Wrong response http code: %v", w.Code)
+	}
+	if got != context.DeadlineExceeded {
+		t.Fatalf("Wrong error: %v", got)
+	}
+}
+
+func TestCanceledHandler(t *testing.T) {
+	router := New()
+
+	router.Context = func(in context.Context) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(in)
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			cancel()
+		}()
+		return ctx, cancel
+	}
+	router.Handle("GET", "/cancel", false, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+	})
+
+	var got error
+	router.CanceledHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		got = err
+		w.WriteHeader(499)
+	}
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/cancel", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != 499 {
+		t.Fatalf("Wrong response http code: %v", w.Code)
+	}
+	if got != context.Canceled {
+		t.Fatalf("Wrong error: %v", got)
+	}
+}
+
+func TestBufferMetrics(t *testing.T) {
+	router := New()
+	router.MaxBufferSize = 8
+
+	body := "0123456789abcdef"
+	router.GET("/big", false, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+
+	var stats BufferStats
+	router.BufferMetrics = func(r *http.Request, s BufferStats) {
+		stats = s
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/big", nil)
+	router.ServeHTTP(w, r)
+	if w.Body.String() != body {
+		t.Fatalf("Wrong body: %v", w.Body.String())
+	}
+	if stats.Disk != int64(len(body)) {
+		t.Fatalf("Wrong stats: %+v", stats)
+	}
+
+	router.BufferOverflow = Stream
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Body.String() != body {
+		t.Fatalf("Wrong body: %v", w.Body.String())
+	}
+	if stats.Streamed != int64(len(body)) || stats.Disk != 0 {
+		t.Fatalf("Wrong stats: %+v", stats)
+	}
+}
+
 func TestLangRedir(t *testing.T) {
 	router := New()
 
@@ -0,0 +1,270 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/fcavani/e"
+)
+
+// Rule maps the paths matching the pattern From, with the :param and
+// *catchall syntax of the routes, to the target To. The parameters of From
+// are replaced in To, for example:
+//  {"from": "/blog/:year/:slug", "to": "/posts/:slug", "code": 301}
+// A rule with a redirect status code, 301, 302, 307 or 308, redirects the
+// client to To, that may be an absolute URL. A rule without code rewrites the
+// path of the request to To and the request is routed again. The query of
+// the request is kept if To has none.
+type Rule struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Code int    `json:"code,omitempty"`
+}
+
+// maxRewrites is the maximum number of rewrites of one request.
+const maxRewrites = 10
+
+// Rules is a set of redirect and rewrite rules, see Router.Rules. The rules
+// are stored in their own tree, like the routes.
+type Rules struct {
+	root      *node
+	rules     map[string]*Rule
+	maxParams uint16
+}
+
+// NewRules creates an empty set of rules.
+func NewRules() *Rules {
+	return &Rules{
+		root:  new(node),
+		rules: make(map[string]*Rule),
+	}
+}
+
+// LoadRules reads the rules from a JSON file with an array of rules.
+func LoadRules(filename string) (*Rules, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, e.Forward(err)
+	}
+	defer f.Close()
+	rs := NewRules()
+	err = rs.Load(f)
+	if err != nil {
+		return nil, e.Forward(err)
+	}
+	return rs, nil
+}
+
+// Load adds the rules read from a JSON array of rules.
+func (rs *Rules) Load(r io.Reader) error {
+	var rules []Rule
+	err := json.NewDecoder(r).Decode(&rules)
+	if err != nil {
+		return e.Push(err, "can't decode the rules")
+	}
+	for _, rule := range rules {
+		err = rs.Add(rule)
+		if err != nil {
+			return e.Forward(err)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of rules.
+func (rs *Rules) Len() int {
+	return len(rs.rules)
+}
+
+func ruleHandle(http.ResponseWriter, *http.Request) {}
+
+// Add adds a rule. It fails if the rule is invalid or its pattern conflicts
+// with the pattern of another rule.
+func (rs *Rules) Add(rule Rule) (err error) {
+	switch rule.Code {
+	case 0, http.StatusMovedPermanently, http.StatusFound,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		return e.New("invalid status code %v in rule %v", rule.Code, rule.From)
+	}
+	if len(rule.From) < 1 || rule.From[0] != '/' {
+		return e.New("pattern must begin with '/' in rule %v", rule.From)
+	}
+	if rule.To == "" {
+		return e.New("rule %v has no target", rule.From)
+	}
+	if rule.Code == 0 && rule.To[0] != '/' {
+		return e.New("rewrite target must begin with '/' in rule %v", rule.From)
+	}
+	if strings.HasPrefix(rule.To, "//") {
+		return e.New("target must not begin with '//' in rule %v", rule.From)
+	}
+	for _, name := range templateParams(rule.To) {
+		if !strings.Contains(rule.From, ":"+name) && !strings.Contains(rule.From, "*"+name) {
+			return e.New("parameter %v of the target isn't in the pattern of rule %v", name, rule.From)
+		}
+	}
+
+	defer func() {
+		if rcv := recover(); rcv != nil {
+			err = e.New("invalid rule %v: %v", rule.From, rcv)
+		}
+	}()
+	rs.root.addRoute(rule.From, false, ruleHandle)
+	rs.root.leaf(rule.From).route = &Route{path: rule.From, handle: ruleHandle}
+	r := rule
+	rs.rules[rule.From] = &r
+	if pc := countParams(rule.From); pc > rs.maxParams {
+		rs.maxParams = pc
+	}
+	return nil
+}
+
+// match returns the rule that matches path and its target. The values of the
+// parameters are escaped and the target never begins with //, so a crafted
+// path can't change the query or the host of the target.
+func (rs *Rules) match(path string) (*Rule, string) {
+	handle, ps, rt, _ := rs.root.getValue(path, func() *Params {
+		ps := make(Params, 0, rs.maxParams+1)
+		return &ps
+	})
+	if handle == nil || rt == nil {
+		return nil, ""
+	}
+	rule := rs.rules[rt.path]
+	if ps == nil {
+		return rule, rule.To
+	}
+	to := expandTemplate(rule.To, escapeParams(rule.To, *ps))
+	if strings.HasPrefix(to, "//") {
+		to = "/" + strings.TrimLeft(to, "/")
+	}
+	return rule, to
+}
+
+// escapeParams returns the parameters with their values escaped to be put in
+// the template tmpl, the values of its catch-all parameters segment by
+// segment.
+func escapeParams(tmpl string, ps Params) Params {
+	escaped := make(Params, len(ps))
+	for i, p := range ps {
+		if !isCatchAll(tmpl, p.Key) {
+			escaped[i] = Param{Key: p.Key, Value: pathEscape(p.Value)}
+			continue
+		}
+		segments := strings.Split(p.Value, "/")
+		for j, s := range segments {
+			segments[j] = pathEscape(s)
+		}
+		escaped[i] = Param{Key: p.Key, Value: strings.Join(segments, "/")}
+	}
+	return escaped
+}
+
+// isCatchAll returns true if name is a catch-all parameter of the template.
+func isCatchAll(tmpl, name string) bool {
+	for i := strings.Index(tmpl, "/*"+name); i >= 0; {
+		end := i + 2 + len(name)
+		if end == len(tmpl) || tmpl[end] == '/' || tmpl[end] == '?' {
+			return true
+		}
+		next := strings.Index(tmpl[end:], "/*"+name)
+		if next < 0 {
+			return false
+		}
+		i = end + next
+	}
+	return false
+}
+
+// pathEscape escapes s to be a segment of a path, like url.PathEscape that
+// isn't available in Go 1.7.
+func pathEscape(s string) string {
+	return strings.Replace((&url.URL{Path: s}).EscapedPath(), "/", "%2F", -1)
+}
+
+// templateParams returns the names of the parameters in the template.
+func templateParams(tmpl string) []string {
+	var names []string
+	for i := 1; i < len(tmpl); i++ {
+		if (tmpl[i] == ':' || tmpl[i] == '*') && tmpl[i-1] == '/' {
+			end := i + 1
+			for end < len(tmpl) && tmpl[end] != '/' && tmpl[end] != '?' {
+				end++
+			}
+			if end > i+1 {
+				names = append(names, tmpl[i+1:end])
+			}
+			i = end
+		}
+	}
+	return names
+}
+
+// expandTemplate replaces the parameters in the template by their values.
+func expandTemplate(tmpl string, ps Params) string {
+	var buf []byte
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		if (c != ':' && c != '*') || i == 0 || tmpl[i-1] != '/' {
+			buf = append(buf, c)
+			continue
+		}
+		end := i + 1
+		for end < len(tmpl) && tmpl[end] != '/' && tmpl[end] != '?' {
+			end++
+		}
+		value := ps.ByName(tmpl[i+1 : end])
+		if c == '*' {
+			// The value of a catch-all parameter begins with a slash.
+			value = strings.TrimPrefix(value, "/")
+		}
+		buf = append(buf, value...)
+		i = end - 1
+	}
+	return string(buf)
+}
+
+// applyRules redirects or rewrites the request. It returns true if the
+// response was sent.
+func (r *Router) applyRules(w http.ResponseWriter, req *http.Request) (*http.Request, bool) {
+	for i := 0; i < maxRewrites; i++ {
+		rule, to := r.Rules.match(req.URL.Path)
+		if rule == nil {
+			return req, false
+		}
+		target, err := url.Parse(to)
+		if err != nil {
+			r.httpError(w, req, http.StatusInternalServerError, "")
+			return req, true
+		}
+		if target.RawQuery == "" {
+			target.RawQuery = req.URL.RawQuery
+		}
+		if rule.Code != 0 {
+			r.sendRedirect(w, req, &RedirectEvent{
+				Kind: RuleRedirect,
+				Code: rule.Code,
+				From: req.URL.String(),
+				To:   target.String(),
+			})
+			return req, true
+		}
+		u := *req.URL
+		u.Path = target.Path
+		u.RawPath = ""
+		u.RawQuery = target.RawQuery
+		req = req.WithContext(req.Context())
+		req.URL = &u
+	}
+	r.httpError(w, req, http.StatusInternalServerError, "Too many rewrites")
+	return req, true
+}
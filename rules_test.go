@@ -0,0 +1,178 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	ps := Params{{"year", "2015"}, {"slug", "hello"}, {"rest", "/a/b"}}
+	tests := []struct {
+		tmpl, want string
+	}{
+		{"/posts/:slug", "/posts/hello"},
+		{"/:year/:slug?from=blog", "/2015/hello?from=blog"},
+		{"/files/*rest", "/files/a/b"},
+		{"https://example.com/:slug", "https://example.com/hello"},
+	}
+	for _, test := range tests {
+		if got := expandTemplate(test.tmpl, ps); got != test.want {
+			t.Errorf("expandTemplate(%q) = %q, want %q", test.tmpl, got, test.want)
+		}
+	}
+	if names := templateParams("https://example.com/:slug/*rest"); len(names) != 2 || names[0] != "slug" || names[1] != "rest" {
+		t.Errorf("wrong template params %v", names)
+	}
+	ps = Params{{"slug", "a/b c"}, {"rest", "/a b/c"}}
+	if got := expandTemplate("/:slug/*rest", escapeParams("/:slug/*rest", ps)); got != "/a%2Fb%20c/a%20b/c" {
+		t.Errorf("wrong escaped template %q", got)
+	}
+	if isCatchAll("/*restful/:rest", "rest") || !isCatchAll("/*restful/*rest?x", "rest") {
+		t.Error("wrong catch-all parameter")
+	}
+}
+
+func TestRulesAdd(t *testing.T) {
+	rs := NewRules()
+	invalid := []Rule{
+		{From: "/a", To: "/b", Code: 200},
+		{From: "a", To: "/b"},
+		{From: "/a", To: ""},
+		{From: "/a", To: "http://example.com/b"},
+		{From: "/a/:id", To: "/b/:name"},
+		{From: "/a", To: "//example.com/b", Code: 301},
+	}
+	for _, rule := range invalid {
+		if err := rs.Add(rule); err == nil {
+			t.Errorf("invalid rule %#v was added", rule)
+		}
+	}
+	if err := rs.Add(Rule{From: "/user/:id", To: "/u/:id"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.Add(Rule{From: "/user/:name", To: "/u/:name"}); err == nil {
+		t.Fatal("conflicting rule was added")
+	}
+	if rs.Len() != 1 {
+		t.Fatalf("wrong number of rules %v", rs.Len())
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "rules.json")
+	err = ioutil.WriteFile(filename, []byte(`[
+		{"from": "/blog/:year/:slug", "to": "/posts/:slug", "code": 301},
+		{"from": "/old/*path", "to": "/static/*path"},
+		{"from": "/loop", "to": "/loop"},
+		{"from": "/elsewhere", "to": "https://example.com/", "code": 302}
+	]`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := LoadRules(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Len() != 4 {
+		t.Fatalf("wrong number of rules %v", rs.Len())
+	}
+
+	router := New()
+	router.Rules = rs
+	var routed string
+	router.GET("/static/*path", false, func(w http.ResponseWriter, r *http.Request) {
+		routed = r.URL.String() + " " + Parameters(r).ByName("path")
+	})
+
+	tests := []struct {
+		url      string
+		code     int
+		location string
+	}{
+		{"/blog/2015/hello?x=1", http.StatusMovedPermanently, "/posts/hello?x=1"},
+		{"/elsewhere", http.StatusFound, "https://example.com/"},
+		{"/old/css/site.css?v=2", http.StatusOK, ""},
+		{"/loop", http.StatusInternalServerError, ""},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != test.code || w.Header().Get("Location") != test.location {
+			t.Errorf("%v: wrong response %v %q", test.url, w.Code, w.Header().Get("Location"))
+		}
+		if r.URL.String() != test.url {
+			t.Errorf("%v: request was modified", test.url)
+		}
+	}
+	if routed != "/static/css/site.css?v=2 /css/site.css" {
+		t.Fatalf("rewrite failed: %q", routed)
+	}
+
+	if _, err := LoadRules(filepath.Join(dir, "none.json")); err == nil {
+		t.Fatal("missing file was loaded")
+	}
+	if err := NewRules().Load(strings.NewReader("{")); err == nil {
+		t.Fatal("invalid json was loaded")
+	}
+}
+
+func TestRulesEscape(t *testing.T) {
+	rs := NewRules()
+	for _, rule := range []Rule{
+		{From: "/legacy/*path", To: "/*path", Code: 301},
+		{From: "/p/:name", To: "/q/:name", Code: 301},
+		{From: "/r/:name", To: "/s/:name"},
+	} {
+		if err := rs.Add(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := New()
+	router.Rules = rs
+	var routed string
+	router.GET("/s/:name", false, func(w http.ResponseWriter, r *http.Request) {
+		routed = r.URL.Path + " " + r.URL.RawQuery
+	})
+
+	tests := []struct {
+		url      string
+		location string
+	}{
+		{"/legacy//evil.com/x", "/evil.com/x"},
+		{"/legacy///evil.com", "/evil.com"},
+		{"/legacy/%5Cevil.com", "/%5Cevil.com"},
+		{"/legacy/a%3Fb=1/c", "/a%3Fb=1/c"},
+		{"/p/a%3Fb=1", "/q/a%3Fb=1"},
+		{"/p/a%23b", "/q/a%23b"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", test.url, nil)
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != test.location {
+			t.Errorf("%v: wrong redirect %v %q", test.url, w.Code, w.Header().Get("Location"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/r/a%3Fb=1", nil)
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || routed != "/s/a?b=1 " {
+		t.Fatalf("wrong rewrite %v %q", w.Code, routed)
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build go1.11
+// +build go1.11
+
+package httprouter
+
+import "net/http"
+
+// httpSameSite returns the http.SameSite of s.
+func httpSameSite(s SameSite) http.SameSite {
+	switch s {
+	case SameSiteDefaultMode:
+		return http.SameSiteDefaultMode
+	case SameSiteLaxMode:
+		return http.SameSiteLaxMode
+	case SameSiteStrictMode:
+		return http.SameSiteStrictMode
+	case SameSiteNoneMode:
+		return sameSiteNone
+	}
+	return 0
+}
+
+func setSameSite(cookie *http.Cookie, s SameSite) {
+	cookie.SameSite = httpSameSite(s)
+}
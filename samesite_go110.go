@@ -0,0 +1,13 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build !go1.11
+// +build !go1.11
+
+package httprouter
+
+import "net/http"
+
+// setSameSite does nothing, http.Cookie has no SameSite before Go 1.11.
+func setSameSite(cookie *http.Cookie, s SameSite) {}
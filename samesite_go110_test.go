@@ -0,0 +1,14 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build !go1.11
+// +build !go1.11
+
+package httprouter
+
+import "net/http"
+
+func hasSameSite(c *http.Cookie, s SameSite) bool {
+	return true
+}
@@ -0,0 +1,13 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build go1.13
+// +build go1.13
+
+package httprouter
+
+import "net/http"
+
+// sameSiteNone is the http.SameSite of SameSiteNoneMode.
+const sameSiteNone = http.SameSiteNoneMode
@@ -0,0 +1,14 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+//go:build go1.11 && !go1.13
+// +build go1.11,!go1.13
+
+package httprouter
+
+import "net/http"
+
+// sameSiteNone omits the attribute, http.SameSiteNoneMode was added in
+// Go 1.13.
+const sameSiteNone http.SameSite = 0
@@ -0,0 +1,161 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/fcavani/e"
+)
+
+// SpanContext is the W3C Trace Context of a span.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	// Trace flags, bit 0 is the sampled flag.
+	Flags byte
+	// Raw value of the tracestate header.
+	State string
+}
+
+// IsValid returns true if the trace and the span ids are not zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// TraceParent returns the value of the traceparent header for the span.
+func (sc SpanContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(sc.TraceID[:]) + "-" +
+		hex.EncodeToString(sc.SpanID[:]) + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// Inject sets the traceparent and tracestate headers, for example in the
+// requests made to other services.
+func (sc SpanContext) Inject(header http.Header) {
+	header.Set("traceparent", sc.TraceParent())
+	if sc.State != "" {
+		header.Set("tracestate", sc.State)
+	} else {
+		header.Del("tracestate")
+	}
+}
+
+// ParseTraceParent parses the value of the traceparent header.
+func ParseTraceParent(s string) (SpanContext, error) {
+	var sc SpanContext
+	s = strings.TrimSpace(s)
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return sc, e.New("invalid traceparent")
+	}
+	var version [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[:2])); err != nil || version[0] == 0xff {
+		return sc, e.New("invalid traceparent version")
+	}
+	if version[0] == 0 && len(s) != 55 {
+		return sc, e.New("invalid traceparent")
+	}
+	if len(s) > 55 && s[55] != '-' {
+		return sc, e.New("invalid traceparent")
+	}
+	if !isLowerHex(s[3:35]) || !isLowerHex(s[36:52]) || !isLowerHex(s[53:55]) {
+		return sc, e.New("invalid traceparent")
+	}
+	hex.Decode(sc.TraceID[:], []byte(s[3:35]))
+	hex.Decode(sc.SpanID[:], []byte(s[36:52]))
+	var flags [1]byte
+	hex.Decode(flags[:], []byte(s[53:55]))
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return sc, e.New("invalid traceparent ids")
+	}
+	return sc, nil
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Tracer starts the spans of the requests served by the router.
+type Tracer interface {
+	// Start is called when the request arrives. parent is the span context
+	// received from the client, it isn't valid if there is none, and sc is
+	// the context of the new span.
+	Start(req *http.Request, parent, sc SpanContext) Span
+}
+
+// Span receives the events of one request. All methods are called from the
+// goroutine running ServeHTTP.
+type Span interface {
+	// Match is called when the request matches a route. The span should be
+	// named after the pattern. It isn't called for requests that don't match
+	// a route.
+	Match(pattern string)
+	// HandlerStart is called before the handle of the route is called.
+	HandlerStart()
+	// HandlerEnd is called after the handle of the route returns.
+	HandlerEnd()
+	// Redirect is called when the router redirects the request.
+	Redirect(code int, location string)
+	// Timeout is called when the context of the request is done before the
+	// handle returns.
+	Timeout(err error)
+	// End is called when the response was sent.
+	End(status int)
+}
+
+type spanKey struct{}
+
+type spanValue struct {
+	sc   SpanContext
+	span Span
+}
+
+// SpanFromContext returns the span context of the request stored in ctx by
+// the router.
+func SpanFromContext(ctx context.Context) (SpanContext, bool) {
+	v, ok := ctx.Value(spanKey{}).(*spanValue)
+	if !ok {
+		return SpanContext{}, false
+	}
+	return v.sc, true
+}
+
+func spanFromRequest(req *http.Request) Span {
+	v, ok := req.Context().Value(spanKey{}).(*spanValue)
+	if !ok {
+		return nil
+	}
+	return v.span
+}
+
+// startSpan reads the trace context of the request, creates the context of
+// the new span and stores both in the request context.
+func (r *Router) startSpan(req *http.Request) (*http.Request, Span) {
+	parent, err := ParseTraceParent(req.Header.Get("traceparent"))
+	sc := SpanContext{Flags: 1}
+	if err == nil {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.State = strings.TrimSpace(strings.Join(req.Header["Tracestate"], ","))
+		parent.State = sc.State
+	} else {
+		parent = SpanContext{}
+		rand.Read(sc.TraceID[:])
+	}
+	rand.Read(sc.SpanID[:])
+	span := r.Tracer.Start(req, parent, sc)
+	v := &spanValue{sc: sc, span: span}
+	return req.WithContext(context.WithValue(req.Context(), spanKey{}, v)), span
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 Felipe A. Cavani. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be found
+// in the LICENSE file.
+
+package httprouter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	valid := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceParent(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.TraceParent() != valid || sc.Flags != 1 {
+		t.Fatalf("wrong span context: %v", sc.TraceParent())
+	}
+	if _, err := ParseTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future"); err != nil {
+		t.Fatal("future version not accepted", err)
+	}
+
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736+00f067aa0ba902b7-01",
+	}
+	for _, s := range invalid {
+		if _, err := ParseTraceParent(s); err == nil {
+			t.Errorf("invalid traceparent accepted: %q", s)
+		}
+	}
+}
+
+type recordTracer struct {
+	parent, sc SpanContext
+	events     []string
+}
+
+func (tr *recordTracer) Start(req *http.Request, parent, sc SpanContext) Span {
+	tr.parent = parent
+	tr.sc = sc
+	tr.events = nil
+	return tr
+}
+
+func (tr *recordTracer) Match(pattern string) { tr.events = append(tr.events, "match "+pattern) }
+func (tr *recordTracer) HandlerStart()        { tr.events = append(tr.events, "start") }
+func (tr *recordTracer) HandlerEnd()          { tr.events = append(tr.events, "end") }
+func (tr *recordTracer) Redirect(code int, location string) {
+	tr.events = append(tr.events, "redirect "+strconv.Itoa(code)+" "+location)
+}
+func (tr *recordTracer) Timeout(err error) { tr.events = append(tr.events, "timeout") }
+func (tr *recordTracer) End(status int) {
+	tr.events = append(tr.events, "status "+strconv.Itoa(status))
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &recordTracer{}
+	router := New()
+	router.Tracer = tracer
+
+	var got SpanContext
+	router.GET("/user/:name", false, func(w http.ResponseWriter, r *http.Request) {
+		got, _ = SpanFromContext(r.Context())
+	})
+	router.GET("/slow", false, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/user/gopher", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set("tracestate", "congo=t61rcWkgMzE")
+	router.ServeHTTP(w, r)
+	if got != tracer.sc || !got.IsValid() {
+		t.Fatalf("wrong span context: %v", got)
+	}
+	if got.TraceID != tracer.parent.TraceID || got.SpanID == tracer.parent.SpanID || got.State != "congo=t61rcWkgMzE" {
+		t.Fatalf("trace not propagated: %v %v", got, tracer.parent)
+	}
+	want := []string{"match /user/:name", "start", "end", "status 200"}
+	if !reflect.DeepEqual(tracer.events, want) {
+		t.Fatalf("wrong events: %v", tracer.events)
+	}
+
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/user/gopher/", nil)
+	router.ServeHTTP(w, r)
+	if tracer.parent.IsValid() || !tracer.sc.IsValid() {
+		t.Fatal("trace not created")
+	}
+	want = []string{"redirect 301 /user/gopher", "status 301"}
+	if !reflect.DeepEqual(tracer.events, want) {
+		t.Fatalf("wrong events: %v", tracer.events)
+	}
+
+	router.Context = func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return context.WithTimeout(ctx, 10*time.Millisecond)
+	}
+	w = httptest.NewRecorder()
+	r, _ = http.NewRequest("GET", "/slow", nil)
+	router.ServeHTTP(w, r)
+	want = []string{"match /slow", "start", "timeout", "status 503"}
+	if !reflect.DeepEqual(tracer.events, want) {
+		t.Fatalf("wrong events: %v", tracer.events)
+	}
+}
@@ -81,6 +81,7 @@ type node struct {
 	children  []*node
 	handle    http.HandlerFunc
 	i18n      bool
+	route     *Route
 }
 
 // Increments priority of the given child and reorders if necessary
@@ -136,6 +137,8 @@ walk:
 				indices:   n.indices,
 				children:  n.children,
 				handle:    n.handle,
+				i18n:      n.i18n,
+				route:     n.route,
 				priority:  n.priority - 1,
 			}
 
@@ -144,6 +147,7 @@ walk:
 			n.indices = string([]byte{n.path[i]})
 			n.path = path[:i]
 			n.handle = nil
+			n.route = nil
 			n.wildChild = false
 		}
 
@@ -328,12 +332,11 @@ func (n *node) insertChild(path, fullPath string, i18n bool, handle http.Handler
 // If no handle can be found, a TSR (trailing slash redirect) recommendation is
 // made if a handle exists with an extra (without the) trailing slash for the
 // given path.
-func (n *node) getValue(path string, params func() *Params) (handle http.HandlerFunc, ps *Params, inter, tsr bool) {
+func (n *node) getValue(path string, params func() *Params) (handle http.HandlerFunc, ps *Params, rt *Route, tsr bool) {
 	var i18n string = "false"
 	if n.i18n == true {
 		i18n = "true"
 	}
-	inter = n.i18n
 	var insertI18n bool = true
 walk: // Outer loop for walking the tree
 	for {
@@ -415,6 +418,7 @@ walk: // Outer loop for walking the tree
 					}
 
 					if handle = n.handle; handle != nil {
+						rt = n.route
 						return
 					} else if len(n.children) == 1 {
 						// No handle found. Check if a handle for this path + a
@@ -452,6 +456,7 @@ walk: // Outer loop for walking the tree
 					}
 
 					handle = n.handle
+					rt = n.route
 					return
 
 				default:
@@ -462,6 +467,7 @@ walk: // Outer loop for walking the tree
 			// We should have reached the node containing the handle.
 			// Check if this node has a handle registered.
 			if handle = n.handle; handle != nil {
+				rt = n.route
 				return
 			}
 
@@ -496,6 +502,31 @@ walk: // Outer loop for walking the tree
 	panic("don't get here")
 }
 
+// Returns the node holding the handle registered with the given path, the
+// path must be written exactly as it was given to addRoute.
+func (n *node) leaf(path string) *node {
+	for {
+		if len(path) < len(n.path) || path[:len(n.path)] != n.path {
+			return nil
+		}
+		path = path[len(n.path):]
+		if path == "" {
+			return n
+		}
+
+		switch {
+		case n.wildChild, n.nType == param && len(n.children) == 1:
+			n = n.children[0]
+		default:
+			i := strings.IndexByte(n.indices, path[0])
+			if i < 0 {
+				return nil
+			}
+			n = n.children[i]
+		}
+	}
+}
+
 // Makes a case-insensitive lookup of the given path and tries to find a handler.
 // It can optionally also fix trailing slashes.
 // It returns the case-corrected path and a bool indicating whether the lookup
@@ -219,6 +219,41 @@ func testRoutes(t *testing.T, routes []testRoute) {
 	//printChildren(tree, "")
 }
 
+func TestTreeLeaf(t *testing.T) {
+	tree := &node{}
+
+	routes := [...]string{
+		"/",
+		"/cmd/:tool/:sub",
+		"/cmd/:tool/",
+		"/src/*filepath",
+		"/search/",
+		"/search/:query",
+		"/user_:name",
+		"/user_:name/about",
+		"/files/:dir/*filepath",
+	}
+	for _, route := range routes {
+		tree.addRoute(route, false, fakeHandler(route))
+	}
+
+	for _, route := range routes {
+		n := tree.leaf(route)
+		if n == nil || n.handle == nil {
+			t.Fatalf("leaf not found for %s", route)
+		}
+		fakeHandlerValue = ""
+		n.handle(nil, nil)
+		if fakeHandlerValue != route {
+			t.Errorf("wrong leaf for %s: %s", route, fakeHandlerValue)
+		}
+	}
+
+	if n := tree.leaf("/nope"); n != nil {
+		t.Errorf("leaf found for /nope")
+	}
+}
+
 func TestTreeWildcardConflict(t *testing.T) {
 	routes := []testRoute{
 		{"/cmd/:tool/:sub", false},